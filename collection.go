@@ -0,0 +1,537 @@
+package structparse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	structTagSliceFormat = "sliceformat"
+	structTagMapFormat   = "mapformat"
+	structTagPairSep     = "pairsep"
+	structTagKVSep       = "kvsep"
+	structTagDuplicates  = "duplicates"
+
+	mapFormatQuery = "query"
+	mapFormatPairs = "pairs"
+	mapFormatJSON  = "json"
+
+	duplicatesError = "error"
+
+	structTagUnique = "unique"
+	structTagMinLen = "minlen"
+	structTagMaxLen = "maxlen"
+
+	mapFormatSet = "set"
+
+	sliceFormatSplit = "split"
+	sliceFormatCSV   = "csv"
+	sliceFormatJSON  = "json"
+
+	sliceOptionTrim      = "trim"
+	sliceOptionOmitEmpty = "omitempty"
+)
+
+// assignSlice splits src into elements according to the sliceformat tag, which has the
+// format <format>[,option...]:
+//   - split (default): elements are separated by the delimiter tag (default ",")
+//   - csv: like split, but elements can be quoted with " and quotes are escaped as ""
+//   - json: a JSON array, e.g. ["a","b,c"]
+//
+// The option trim removes whitespace around elements and omitempty drops empty elements.
+func assignSlice(dst reflect.Value, src string, tag reflect.StructTag) error {
+	typ := dst.Type()
+	if len(src) == 0 {
+		return nil
+	}
+	if typ.Elem().Kind() == reflect.Uint8 {
+		// handle []byte slices as string
+		dst.SetBytes([]byte(src))
+		return nil
+	}
+
+	delimiter, elemTag := splitDelimiterLevels(tag, typ.Elem())
+	parts, err := splitSliceElements(src, delimiter, tag)
+	if err != nil {
+		return &AssignError{Cause: err, Msg: "cannot parse as slice"}
+	}
+
+	sl := reflect.MakeSlice(typ, len(parts), len(parts))
+	for i, p := range parts {
+		err := assignValue(sl.Index(i), p, elemTag)
+		if err != nil {
+			return err
+		}
+	}
+	dst.Set(sl)
+	return nil
+}
+
+// assignArray parses src like a slice, but requires exactly as many elements as the array has.
+func assignArray(dst reflect.Value, src string, tag reflect.StructTag) error {
+	typ := dst.Type()
+
+	delimiter, elemTag := splitDelimiterLevels(tag, typ.Elem())
+	parts, err := splitSliceElements(src, delimiter, tag)
+	if err != nil {
+		return &AssignError{Cause: err, Msg: "cannot parse as array"}
+	}
+	if len(src) == 0 {
+		parts = nil
+	}
+	if len(parts) != typ.Len() {
+		return &AssignError{
+			Cause: fmt.Errorf("expected %d elements but got %d", typ.Len(), len(parts)),
+			Msg:   "cannot parse as array",
+		}
+	}
+
+	for i, p := range parts {
+		err := assignValue(dst.Index(i), p, elemTag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitDelimiterLevels returns the delimiter of the current level and the tag to parse
+// elements of elemType with. If elements are collections themselves and the delimiter
+// has multiple characters, each character is the delimiter of one level, e.g. ";,"
+// splits [][]int at ";" and the inner slices at ",". For maps, the following characters
+// are used as pairsep and kvsep.
+func splitDelimiterLevels(tag reflect.StructTag, elemType reflect.Type) (string, reflect.StructTag) {
+	delimiter := tag.Get(structTagDelimiter)
+	if len(delimiter) == 0 {
+		delimiter = ","
+	}
+
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	isCollection := false
+	switch elemType.Kind() {
+	case reflect.Slice:
+		isCollection = elemType.Elem().Kind() != reflect.Uint8
+	case reflect.Array, reflect.Map:
+		isCollection = true
+	}
+	if _, ok := getCustomParser(elemType); ok {
+		isCollection = false
+	}
+
+	runes := []rune(delimiter)
+	if !isCollection || len(runes) < 2 {
+		return delimiter, tag
+	}
+
+	outer, inner := string(runes[0]), runes[1:]
+	if elemType.Kind() == reflect.Map {
+		tag = withTag(tag, structTagPairSep, string(inner[0]))
+		inner = inner[1:]
+		if len(inner) > 0 {
+			tag = withTag(tag, structTagKVSep, string(inner[0]))
+			inner = inner[1:]
+		}
+		if len(inner) == 0 {
+			return outer, tag
+		}
+	}
+	return outer, withTag(tag, structTagDelimiter, string(inner))
+}
+
+// withTag returns tag with key set to value. As reflect.StructTag.Lookup returns the
+// first match, prepending the key overrides existing values.
+func withTag(tag reflect.StructTag, key, value string) reflect.StructTag {
+	return reflect.StructTag(fmt.Sprintf("%s:%s %s", key, strconv.Quote(value), tag))
+}
+
+func splitSliceElements(src, delimiter string, tag reflect.StructTag) ([]string, error) {
+	options := strings.Split(tag.Get(structTagSliceFormat), ",")
+	var trim, omitEmpty bool
+	for _, option := range options[1:] {
+		switch strings.TrimSpace(option) {
+		case sliceOptionTrim:
+			trim = true
+		case sliceOptionOmitEmpty:
+			omitEmpty = true
+		default:
+			return nil, fmt.Errorf("unsupported slice option %s", option)
+		}
+	}
+
+	var (
+		parts []string
+		err   error
+	)
+	switch format := strings.TrimSpace(options[0]); format {
+	case "", sliceFormatSplit:
+		parts = strings.Split(src, delimiter)
+		if trim {
+			for i := range parts {
+				parts[i] = strings.TrimSpace(parts[i])
+			}
+		}
+	case sliceFormatCSV:
+		parts, err = splitCSV(src, delimiter, trim)
+	case sliceFormatJSON:
+		parts, err = parseJSONArrayElements(src)
+	default:
+		return nil, fmt.Errorf("unsupported slice format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if omitEmpty {
+		filtered := parts[:0]
+		for _, p := range parts {
+			if len(p) > 0 {
+				filtered = append(filtered, p)
+			}
+		}
+		parts = filtered
+	}
+	return parts, nil
+}
+
+// splitCSV splits src at delimiter while respecting elements quoted with ". Quotes inside
+// quoted elements are escaped by doubling them. If trim is set, whitespace outside of
+// quotes is removed.
+func splitCSV(src, delimiter string, trim bool) ([]string, error) {
+	var (
+		parts   []string
+		sb      strings.Builder
+		quoted  bool
+		inQuote bool
+	)
+	finish := func() {
+		part := sb.String()
+		if trim && !quoted {
+			part = strings.TrimSpace(part)
+		}
+		parts = append(parts, part)
+		sb.Reset()
+		quoted = false
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case inQuote && c == '"':
+			if i+1 < len(src) && src[i+1] == '"' {
+				sb.WriteByte('"')
+				i++
+			} else {
+				inQuote = false
+			}
+		case inQuote:
+			sb.WriteByte(c)
+		case c == '"':
+			// without trim, whitespace around quoted elements is rejected on both sides
+			before := sb.String()
+			if trim {
+				before = strings.TrimSpace(before)
+			}
+			if quoted || len(before) > 0 {
+				return nil, fmt.Errorf("unexpected quote at position %d", i)
+			}
+			sb.Reset()
+			quoted, inQuote = true, true
+		case strings.HasPrefix(src[i:], delimiter):
+			finish()
+			i += len(delimiter) - 1
+		case quoted:
+			if !trim || c != ' ' && c != '\t' {
+				return nil, fmt.Errorf("unexpected character after quoted element at position %d", i)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if inQuote {
+		return nil, errors.New("unterminated quoted element")
+	}
+	finish()
+	return parts, nil
+}
+
+func parseJSONArrayElements(src string) ([]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(src), &raw); err != nil {
+		return nil, err
+	}
+	parts := make([]string, len(raw))
+	for i, r := range raw {
+		parts[i] = jsonValueString(r)
+	}
+	return parts, nil
+}
+
+type mapEntry struct {
+	key   string
+	value string
+}
+
+// isSet reports whether a map is parsed from a delimited list of its keys. This is always
+// the case for map[K]struct{} and for map[K]bool if mapformat:"set" is set.
+func isSet(typ reflect.Type, tag reflect.StructTag) bool {
+	elem := typ.Elem()
+	switch {
+	case elem.Kind() == reflect.Struct && elem.NumField() == 0:
+		return true
+	case elem.Kind() == reflect.Bool:
+		return tag.Get(structTagMapFormat) == mapFormatSet
+	}
+	return false
+}
+
+// assignSet parses src like a slice and adds every element as a key to the map.
+func assignSet(dst reflect.Value, src string, tag reflect.StructTag) error {
+	typ := dst.Type()
+
+	m := reflect.MakeMap(typ)
+	dst.Set(m)
+	if len(src) == 0 {
+		return nil
+	}
+
+	delimiter := tag.Get(structTagDelimiter)
+	if len(delimiter) == 0 {
+		delimiter = ","
+	}
+	parts, err := splitSliceElements(src, delimiter, tag)
+	if err != nil {
+		return &AssignError{Cause: err, Msg: "cannot parse as set"}
+	}
+
+	elemVal := reflect.New(typ.Elem()).Elem()
+	if elemVal.Kind() == reflect.Bool {
+		elemVal.SetBool(true)
+	}
+
+	unique := tag.Get(structTagUnique) == "true" || tag.Get(structTagDuplicates) == duplicatesError
+	for _, p := range parts {
+		keyVal := reflect.New(typ.Key())
+		err := assignValue(keyVal, p, tag)
+		if err != nil {
+			return err
+		}
+		if unique && m.MapIndex(keyVal.Elem()).IsValid() {
+			return &ValidationError{Rule: structTagUnique, Msg: fmt.Sprintf("duplicate element %q", p)}
+		}
+		m.SetMapIndex(keyVal.Elem(), elemVal)
+	}
+	return nil
+}
+
+// validateCollection checks the unique, minlen and maxlen constraints of slices, arrays and maps.
+func validateCollection(v reflect.Value, tag reflect.StructTag) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return nil
+	}
+
+	for _, rule := range []string{structTagMinLen, structTagMaxLen} {
+		limit, ok := tag.Lookup(rule)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return fmt.Errorf("invalid %s tag: %w", rule, err)
+		}
+		if rule == structTagMinLen && v.Len() < n {
+			return &ValidationError{Rule: rule, Msg: fmt.Sprintf("expected at least %d elements but got %d", n, v.Len())}
+		}
+		if rule == structTagMaxLen && v.Len() > n {
+			return &ValidationError{Rule: rule, Msg: fmt.Sprintf("expected at most %d elements but got %d", n, v.Len())}
+		}
+	}
+
+	if tag.Get(structTagUnique) == "true" && v.Kind() != reflect.Map {
+		for i := 0; i < v.Len(); i++ {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(v.Index(i).Interface(), v.Index(j).Interface()) {
+					return &ValidationError{Rule: structTagUnique, Msg: fmt.Sprintf("duplicate element %v", v.Index(i).Interface())}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// assignMap parses src according to the mapformat tag:
+//
+//   - query (default): url query syntax, e.g. a=1&b=2
+//
+//   - pairs: key value pairs separated by pairsep (default ",") and kvsep (default "="), e.g. a=1,b=2
+//
+//   - json: a JSON object, e.g. {"a":1,"b":"2"}
+//
+//   - set: a delimited list of keys, see isSet
+//
+// Duplicate keys keep the first value unless duplicates:"error" is set. For map[K][]V,
+// the values of repeated keys are appended instead.
+func assignMap(dst reflect.Value, src string, tag reflect.StructTag) error {
+	typ := dst.Type()
+
+	if isSet(typ, tag) {
+		return assignSet(dst, src, tag)
+	}
+
+	entries, err := parseMapEntries(src, tag)
+	if err != nil {
+		return &AssignError{Cause: err, Msg: "cannot parse as map"}
+	}
+
+	var (
+		m           = reflect.MakeMap(typ)
+		errOnDup    = tag.Get(structTagDuplicates) == duplicatesError
+		appendToDup = typ.Elem().Kind() == reflect.Slice && typ.Elem().Elem().Kind() != reflect.Uint8
+	)
+	for _, entry := range entries {
+		keyVal := reflect.New(typ.Key())
+		err := assignValue(keyVal, entry.key, tag)
+		if err != nil {
+			return err
+		}
+
+		existing := m.MapIndex(keyVal.Elem())
+		if existing.IsValid() {
+			if errOnDup {
+				return &AssignError{Cause: fmt.Errorf("duplicate key %q", entry.key), Msg: "cannot parse as map"}
+			}
+			if !appendToDup {
+				continue
+			}
+		}
+
+		elemVal := reflect.New(typ.Elem())
+		err = assignValue(elemVal, entry.value, tag)
+		if err != nil {
+			return err
+		}
+		if existing.IsValid() {
+			elemVal.Elem().Set(reflect.AppendSlice(existing, elemVal.Elem()))
+		}
+		m.SetMapIndex(keyVal.Elem(), elemVal.Elem())
+	}
+
+	dst.Set(m)
+	return nil
+}
+
+func parseMapEntries(src string, tag reflect.StructTag) ([]mapEntry, error) {
+	switch format := tag.Get(structTagMapFormat); format {
+	case "", mapFormatQuery:
+		values, err := url.ParseQuery(src)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var entries []mapEntry
+		for _, k := range keys {
+			for _, v := range values[k] {
+				entries = append(entries, mapEntry{key: k, value: v})
+			}
+		}
+		return entries, nil
+
+	case mapFormatPairs:
+		if len(src) == 0 {
+			return nil, nil
+		}
+		pairSep, ok := tag.Lookup(structTagPairSep)
+		if !ok {
+			pairSep = ","
+		}
+		kvSep, ok := tag.Lookup(structTagKVSep)
+		if !ok {
+			kvSep = "="
+		}
+
+		var entries []mapEntry
+		for _, pair := range strings.Split(src, pairSep) {
+			kv := strings.SplitN(pair, kvSep, 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("missing %q in pair %q", kvSep, pair)
+			}
+			entries = append(entries, mapEntry{key: kv[0], value: kv[1]})
+		}
+		return entries, nil
+
+	case mapFormatJSON:
+		return parseJSONObjectEntries(src)
+
+	default:
+		return nil, fmt.Errorf("unsupported map format %s", format)
+	}
+}
+
+// parseJSONObjectEntries decodes a JSON object token by token to preserve duplicate keys.
+// String values are unquoted, all other values are kept as raw JSON.
+func parseJSONObjectEntries(src string) ([]mapEntry, error) {
+	dec := json.NewDecoder(strings.NewReader(src))
+	dec.UseNumber()
+
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, errors.New("expected JSON object")
+	}
+
+	var entries []mapEntry
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v", token)
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		entries = append(entries, mapEntry{key: key, value: jsonValueString(raw)})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON object")
+	}
+	return entries, nil
+}
+
+func jsonValueString(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+	return string(raw)
+}
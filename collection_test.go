@@ -0,0 +1,204 @@
+package structparse
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAssignMap(t *testing.T) {
+	var dummy struct {
+		Query        map[string]int
+		Pairs        map[string]int    `mapformat:"pairs"`
+		PairsSep     map[string]string `mapformat:"pairs" pairsep:";" kvsep:":"`
+		JSON         map[string]string `mapformat:"json"`
+		Multi        map[string][]int
+		MultiPairs   map[string][]int `mapformat:"pairs" pairsep:"&"`
+		FirstWins    map[string]int   `mapformat:"pairs"`
+		Duplicate    map[string]int   `mapformat:"pairs" duplicates:"error"`
+		MissingSep   map[string]int   `mapformat:"pairs"`
+		InvalidJSON  map[string]int   `mapformat:"json"`
+		TrailingJSON map[string]int   `mapformat:"json"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"query":         "a=1&b=2",
+			"pairs":         "a=1,b=2",
+			"pairs-sep":     "a:x=1;b:y,2",
+			"json":          `{"a":"x,y","b":2,"c":true}`,
+			"multi":         "a=1&a=2&b=3",
+			"multi-pairs":   "a=1,2&a=3",
+			"first-wins":    "a=1,a=2",
+			"duplicate":     "a=1,a=2",
+			"missing-sep":   "a=1,b",
+			"invalid-json":  "[1]",
+			"trailing-json": `{"a":1} garbage`,
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: duplicate] [field: Duplicate] cannot parse as map: duplicate key \"a\", "+
+		"[key: missing-sep] [field: MissingSep] cannot parse as map: missing \"=\" in pair \"b\", "+
+		"[key: invalid-json] [field: InvalidJSON] cannot parse as map: expected JSON object, "+
+		"[key: trailing-json] [field: TrailingJSON] cannot parse as map: unexpected data after JSON object", err)
+
+	assertEqual(t, map[string]int{"a": 1, "b": 2}, dummy.Query)
+	assertEqual(t, map[string]int{"a": 1, "b": 2}, dummy.Pairs)
+	assertEqual(t, map[string]string{"a": "x=1", "b": "y,2"}, dummy.PairsSep)
+	assertEqual(t, map[string]string{"a": "x,y", "b": "2", "c": "true"}, dummy.JSON)
+	assertEqual(t, map[string][]int{"a": {1, 2}, "b": {3}}, dummy.Multi)
+	assertEqual(t, map[string][]int{"a": {1, 2, 3}}, dummy.MultiPairs)
+	assertEqual(t, map[string]int{"a": 1}, dummy.FirstWins)
+}
+
+func TestAssignSlice(t *testing.T) {
+	var dummy struct {
+		Split        []string
+		Trim         []string `sliceformat:"split,trim"`
+		OmitEmpty    []int    `sliceformat:",trim,omitempty"`
+		CSV          []string `sliceformat:"csv"`
+		CSVTrim      []string `sliceformat:"csv,trim" delimiter:";"`
+		CSVOmit      []string `sliceformat:"csv,omitempty"`
+		JSON         []string `sliceformat:"json"`
+		JSONInts     []int    `sliceformat:"json"`
+		Unterminated []string `sliceformat:"csv"`
+		SpaceBefore  []string `sliceformat:"csv"`
+		SpaceAfter   []string `sliceformat:"csv"`
+		InvalidJSON  []string `sliceformat:"json"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"split":        " a, b ",
+			"trim":         " a, b ",
+			"omit-empty":   "1, ,2,",
+			"csv":          `a,"b,c","say ""hi""",""`,
+			"csv-trim":     ` a ; " b;c " ;d`,
+			"csv-omit":     `a,,"",b`,
+			"json":         `["a","b,c"]`,
+			"json-ints":    `[1,2]`,
+			"unterminated": `a,"b`,
+			"space-before": `a, "b"`,
+			"space-after":  `"a" ,b`,
+			"invalid-json": `["a"`,
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: unterminated] [field: Unterminated] cannot parse as slice: unterminated quoted element, "+
+		"[key: space-before] [field: SpaceBefore] cannot parse as slice: unexpected quote at position 3, "+
+		"[key: space-after] [field: SpaceAfter] cannot parse as slice: unexpected character after quoted element at position 3, "+
+		"[key: invalid-json] [field: InvalidJSON] cannot parse as slice: unexpected end of JSON input", err)
+
+	assertEqual(t, 2, len(dummy.Split))
+	assertEqual(t, " b ", dummy.Split[1])
+	assertEqual(t, []string{"a", "b"}, dummy.Trim)
+	assertEqual(t, []int{1, 2}, dummy.OmitEmpty)
+	assertEqual(t, 4, len(dummy.CSV))
+	assertEqual(t, "b,c", dummy.CSV[1])
+	assertEqual(t, `say "hi"`, dummy.CSV[2])
+	assertEqual(t, "", dummy.CSV[3])
+	assertEqual(t, 3, len(dummy.CSVTrim))
+	assertEqual(t, " b;c ", dummy.CSVTrim[1])
+	assertEqual(t, "d", dummy.CSVTrim[2])
+	assertEqual(t, []string{"a", "b"}, dummy.CSVOmit)
+	assertEqual(t, 2, len(dummy.JSON))
+	assertEqual(t, "b,c", dummy.JSON[1])
+	assertEqual(t, []int{1, 2}, dummy.JSONInts)
+}
+
+func TestAssignNested(t *testing.T) {
+	var dummy struct {
+		RGB          [3]float64
+		Matrix       [][]int          `delimiter:";,"`
+		Arrays       [][2]int         `delimiter:";,"`
+		Deep         [][][]int        `delimiter:"|;,"`
+		Maps         []map[string]int `delimiter:";,:" mapformat:"pairs"`
+		MultiChar    []int            `delimiter:"||"`
+		TooShort     [3]float64
+		TooLong      [3]float64
+		InvalidInner [][]int `delimiter:";,"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"rgb":           "0.1,0.2,0.3",
+			"matrix":        "1,2;3,4,5",
+			"arrays":        "1,2;3,4",
+			"deep":          "1,2;3|4",
+			"maps":          "a:1,b:2;c:3",
+			"multi-char":    "1||2",
+			"too-short":     "0.1,0.2",
+			"too-long":      "0.1,0.2,0.3,0.4",
+			"invalid-inner": "1,2;a",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: too-short] [field: TooShort] cannot parse as array: expected 3 elements but got 2, "+
+		"[key: too-long] [field: TooLong] cannot parse as array: expected 3 elements but got 4, "+
+		"[key: invalid-inner] [field: InvalidInner] cannot parse as int: strconv.ParseInt: parsing \"a\": invalid syntax", err)
+
+	assertEqual(t, [3]float64{0.1, 0.2, 0.3}, dummy.RGB)
+	assertEqual(t, [][]int{{1, 2}, {3, 4, 5}}, dummy.Matrix)
+	assertEqual(t, [][2]int{{1, 2}, {3, 4}}, dummy.Arrays)
+	assertEqual(t, [][][]int{{{1, 2}, {3}}, {{4}}}, dummy.Deep)
+	assertEqual(t, []map[string]int{{"a": 1, "b": 2}, {"c": 3}}, dummy.Maps)
+	assertEqual(t, []int{1, 2}, dummy.MultiChar)
+}
+
+func TestAssignSet(t *testing.T) {
+	var dummy struct {
+		Struct      map[string]struct{}
+		Bool        map[string]bool `mapformat:"set"`
+		BoolQuery   map[string]bool
+		BoolSet     map[string]bool  `mapformat:"set" delimiter:";"`
+		Ints        map[int]struct{} `sliceformat:",trim"`
+		Empty       map[string]struct{}
+		Unique      map[string]struct{} `unique:"true"`
+		UniqueSlice []string            `unique:"true"`
+		MinLen      []int               `minlen:"2"`
+		MaxLen      map[string]bool     `mapformat:"set" maxlen:"1"`
+		Valid       []int               `unique:"true" minlen:"1" maxlen:"3"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"struct":       "a,b,a",
+			"bool":         "a,b",
+			"bool-query":   "a=true&b=false",
+			"bool-set":     "a=1;b",
+			"ints":         "1, 2",
+			"empty":        "",
+			"unique":       "a,b,a",
+			"unique-slice": "a,b,b",
+			"min-len":      "1",
+			"max-len":      "a,b",
+			"valid":        "1,2,3",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: unique] [field: Unique] duplicate element \"a\", "+
+		"[key: unique-slice] [field: UniqueSlice] duplicate element b, "+
+		"[key: min-len] [field: MinLen] expected at least 2 elements but got 1, "+
+		"[key: max-len] [field: MaxLen] expected at most 1 elements but got 2", err)
+
+	var parseErr ParseError
+	if errors.As(err, &parseErr) {
+		var validationErr *ValidationError
+		if assertEqual(t, true, errors.As(parseErr[1], &validationErr)) {
+			assertEqual(t, "unique", validationErr.Rule)
+			assertEqual(t, "unique-slice", validationErr.Key)
+			assertEqual(t, "UniqueSlice", validationErr.FieldPath)
+		}
+	}
+
+	assertEqual(t, map[string]struct{}{"a": {}, "b": {}}, dummy.Struct)
+	assertEqual(t, map[string]bool{"a": true, "b": true}, dummy.Bool)
+	assertEqual(t, map[string]bool{"a": true, "b": false}, dummy.BoolQuery)
+	assertEqual(t, map[string]bool{"a=1": true, "b": true}, dummy.BoolSet)
+	assertEqual(t, map[int]struct{}{1: {}, 2: {}}, dummy.Ints)
+	assertEqual(t, 0, len(dummy.Empty))
+	assertEqual(t, []int{1, 2, 3}, dummy.Valid)
+}
@@ -0,0 +1,119 @@
+package structparse
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	AlgorithmAES256GCM = "AES256_GCM"
+
+	encryptedPrefix = "ENC["
+	encryptedSuffix = "]"
+	encryptedData   = "data:"
+)
+
+var (
+	ErrMalformedEncryptedValue = errors.New("malformed encrypted value")
+)
+
+type Decrypter interface {
+	Decrypt(algorithm string, data []byte) ([]byte, error)
+}
+
+type Encrypter interface {
+	Encrypt(plaintext []byte) (algorithm string, data []byte, err error)
+}
+
+// AESGCM encrypts and decrypts values with AES-256 in GCM mode. The nonce is
+// prepended to the ciphertext.
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM creates an AESGCM from a 32 byte key.
+func NewAESGCM(key []byte) (*AESGCM, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid key size %d, expected 32 bytes", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCM{aead: aead}, nil
+}
+
+func (c *AESGCM) Encrypt(plaintext []byte) (string, []byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", nil, err
+	}
+	return AlgorithmAES256GCM, c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c *AESGCM) Decrypt(algorithm string, data []byte) ([]byte, error) {
+	if algorithm != AlgorithmAES256GCM {
+		return nil, fmt.Errorf("unsupported algorithm %s", algorithm)
+	}
+	if len(data) < c.aead.NonceSize() {
+		return nil, ErrMalformedEncryptedValue
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, ciphertext, nil)
+}
+
+// EncryptValue encrypts plaintext into the ENC[<algorithm>,data:<base64>] format
+// understood by TransformerDecrypt.
+func EncryptValue(enc Encrypter, plaintext string) (string, error) {
+	algorithm, data, err := enc.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return encryptedPrefix + algorithm + "," + encryptedData + base64.StdEncoding.EncodeToString(data) + encryptedSuffix, nil
+}
+
+// TransformerDecrypt decrypts values in the ENC[<algorithm>,data:<base64>] format.
+// All other values are passed through.
+func TransformerDecrypt(dec Decrypter) Transformer {
+	return FieldTransformFunc(func(field Field, srcValue string, srcErr error) (string, error) {
+		if srcErr != nil || !strings.HasPrefix(srcValue, encryptedPrefix) {
+			return srcValue, srcErr
+		}
+		algorithm, data, err := parseEncryptedValue(srcValue)
+		if err != nil {
+			return "", err
+		}
+		plaintext, err := dec.Decrypt(algorithm, data)
+		if err != nil {
+			return "", fmt.Errorf("cannot decrypt value: %w", err)
+		}
+		return string(plaintext), nil
+	})
+}
+
+func parseEncryptedValue(value string) (string, []byte, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) || !strings.HasSuffix(value, encryptedSuffix) {
+		return "", nil, ErrMalformedEncryptedValue
+	}
+	value = value[len(encryptedPrefix) : len(value)-len(encryptedSuffix)]
+
+	parts := strings.SplitN(value, ",", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], encryptedData) {
+		return "", nil, ErrMalformedEncryptedValue
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(parts[1], encryptedData))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrMalformedEncryptedValue, err)
+	}
+	return parts[0], data, nil
+}
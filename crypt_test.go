@@ -0,0 +1,54 @@
+package structparse
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTransformerDecrypt(t *testing.T) {
+	c, err := NewAESGCM(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	encrypted, err := EncryptValue(c, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	assertEqual(t, true, strings.HasPrefix(encrypted, "ENC[AES256_GCM,data:"))
+
+	other, err := NewAESGCM(bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	wrongKey, err := EncryptValue(other, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var dummy struct {
+		Encrypted string
+		Plain     string
+		Malformed string
+		WrongKey  string
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"encrypted": encrypted,
+			"plain":     "value",
+			"malformed": "ENC[AES256_GCM,invalid]",
+			"wrong-key": wrongKey,
+		},
+		KeyFmt:       KeyFmtKebab(),
+		Transformers: []Transformer{TransformerDecrypt(c)},
+	}
+	err = Parse(cfg, &dummy)
+	assertEqual(t, "[key: malformed] [field: Malformed] malformed encrypted value, "+
+		"[key: wrong-key] [field: WrongKey] cannot decrypt value: cipher: message authentication failed", err)
+	assertEqual(t, "secret", dummy.Encrypted)
+	assertEqual(t, "value", dummy.Plain)
+
+	_, err = NewAESGCM([]byte("short"))
+	assertEqual(t, "invalid key size 5, expected 32 bytes", err)
+}
@@ -11,19 +11,35 @@ type Parser func(value string, tag reflect.StructTag) (interface{}, error)
 var (
 	customParsersMu sync.Mutex
 	customParsers   = make(map[string]Parser)
+	builtinParsers  = make(map[string]struct{})
 )
 
+// RegisterCustomParser registers fn for values of the type of typ. Built-in parsers are
+// replaced, registering a second parser for any other type panics.
 func RegisterCustomParser(typ interface{}, fn Parser) {
 	customParsersMu.Lock()
 	defer customParsersMu.Unlock()
 
 	name := getCustomParserName(reflect.TypeOf(typ))
 	if _, ok := customParsers[name]; ok {
-		panic(fmt.Sprintf("custom parser already registered for: %s", name))
+		if _, builtin := builtinParsers[name]; !builtin {
+			panic(fmt.Sprintf("custom parser already registered for: %s", name))
+		}
+		delete(builtinParsers, name)
 	}
 	customParsers[name] = fn
 }
 
+// markBuiltinParsers marks all parsers registered so far as built-in.
+func markBuiltinParsers() {
+	customParsersMu.Lock()
+	defer customParsersMu.Unlock()
+
+	for name := range customParsers {
+		builtinParsers[name] = struct{}{}
+	}
+}
+
 func getCustomParserName(typ reflect.Type) string {
 	if typ.Kind() == reflect.Ptr {
 		typ = typ.Elem()
@@ -1,6 +1,7 @@
 package structparse
 
 import (
+	"os"
 	"reflect"
 	"testing"
 )
@@ -32,3 +33,31 @@ func TestRegisterCustomParser(t *testing.T) {
 		RegisterCustomParser((*dummy)(nil), nil)
 	}()
 }
+
+func TestRegisterCustomParser_ReplaceBuiltin(t *testing.T) {
+	typ := reflect.TypeOf(os.FileMode(0))
+	builtin, _ := getCustomParser(typ)
+	defer func() {
+		customParsersMu.Lock()
+		defer customParsersMu.Unlock()
+		customParsers[getCustomParserName(typ)] = builtin
+		builtinParsers[getCustomParserName(typ)] = struct{}{}
+	}()
+
+	RegisterCustomParser(os.FileMode(0), func(value string, _ reflect.StructTag) (interface{}, error) {
+		return os.FileMode(0777), nil
+	})
+	var dummy struct{ Mode os.FileMode }
+	err := Parse(Config{Src: SourceMap{"MODE": "0644"}, KeyFmt: KeyFmtEnv()}, &dummy)
+	assertEqual(t, nil, err)
+	assertEqual(t, os.FileMode(0777), dummy.Mode)
+
+	// only the built-in parser can be replaced
+	func() {
+		defer func() {
+			p := recover()
+			assertEqual(t, "custom parser already registered for: io/fs/FileMode", p)
+		}()
+		RegisterCustomParser(os.FileMode(0), nil)
+	}()
+}
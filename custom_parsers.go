@@ -1,23 +1,47 @@
 package structparse
 
 import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
-	structTagLayout = "layout"
+	structTagLayout   = "layout"
+	structTagTimeZone = "tz"
+	structTagScheme   = "scheme"
 )
 
 func init() {
+	// the built-in parsers can be replaced by registering a custom parser for the same type
+	defer markBuiltinParsers()
+
 	RegisterCustomParser(time.Duration(0), func(value string, tag reflect.StructTag) (interface{}, error) {
-		v, err := time.ParseDuration(value)
+		v, err := parseDuration(value, tag)
 		if err != nil {
 			return nil, err
 		}
 		return v, nil
 	})
 
+	RegisterCustomParser(ByteSize(0), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, err := parseQuantity(value, unitBytes, 64, false)
+		if err != nil {
+			return nil, err
+		}
+		return ByteSize(v.Uint64()), nil
+	})
+
 	timeLayoutMapping := map[string]string{
 		"ANSIC":       time.ANSIC,
 		"UnixDate":    time.UnixDate,
@@ -37,17 +61,173 @@ func init() {
 	}
 
 	RegisterCustomParser(time.Time{}, func(value string, tag reflect.StructTag) (interface{}, error) {
-		layout := tag.Get(structTagLayout)
-		if mapped, ok := timeLayoutMapping[layout]; ok {
-			layout = mapped
+		loc := time.UTC
+		if tz, ok := tag.Lookup(structTagTimeZone); ok {
+			var err error
+			loc, err = time.LoadLocation(tz)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		layouts := strings.Split(tag.Get(structTagLayout), "|")
+		var attempted []string
+		for _, layout := range layouts {
+			if mapped, ok := timeLayoutMapping[layout]; ok {
+				layout = mapped
+			}
+			if len(layout) == 0 {
+				layout = time.RFC3339
+			}
+
+			v, err := parseTime(value, layout, loc)
+			if err == nil {
+				return v, nil
+			}
+			if len(layouts) == 1 {
+				return nil, err
+			}
+			attempted = append(attempted, fmt.Sprintf("%q", layout))
+		}
+		return nil, fmt.Errorf("value %q does not match any layout: %s", value, strings.Join(attempted, ", "))
+	})
+
+	RegisterCustomParser(net.IP{}, func(value string, tag reflect.StructTag) (interface{}, error) {
+		v := net.ParseIP(value)
+		if v == nil {
+			return nil, fmt.Errorf("invalid IP address %q", value)
+		}
+		return v, nil
+	})
+
+	RegisterCustomParser((*net.IPNet)(nil), func(value string, tag reflect.StructTag) (interface{}, error) {
+		_, v, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+
+	RegisterCustomParser(netip.Addr{}, func(value string, tag reflect.StructTag) (interface{}, error) {
+		return netip.ParseAddr(value)
+	})
+
+	RegisterCustomParser(netip.Prefix{}, func(value string, tag reflect.StructTag) (interface{}, error) {
+		return netip.ParsePrefix(value)
+	})
+
+	RegisterCustomParser(netip.AddrPort{}, func(value string, tag reflect.StructTag) (interface{}, error) {
+		return netip.ParseAddrPort(value)
+	})
+
+	RegisterCustomParser((*url.URL)(nil), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, err := url.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		if schemes, ok := tag.Lookup(structTagScheme); ok {
+			allowed := strings.Split(schemes, ",")
+			for _, scheme := range allowed {
+				if strings.EqualFold(strings.TrimSpace(scheme), v.Scheme) {
+					return v, nil
+				}
+			}
+			return nil, fmt.Errorf("scheme %q is not one of: %s", v.Scheme, strings.Join(allowed, ", "))
+		}
+		return v, nil
+	})
+
+	RegisterCustomParser((*regexp.Regexp)(nil), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
 		}
-		if len(layout) == 0 {
-			layout = time.RFC3339
+		return v, nil
+	})
+
+	RegisterCustomParser((*time.Location)(nil), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, err := time.LoadLocation(value)
+		if err != nil {
+			return nil, err
 		}
-		v, err := time.Parse(layout, value)
+		return v, nil
+	})
+
+	// file modes are always parsed as octal numbers, e.g. 644, 0644 or 0o644. The unix
+	// setuid, setgid and sticky bits are mapped to their os.FileMode counterparts.
+	RegisterCustomParser(os.FileMode(0), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(value, "0o"), "0O"), 8, 32)
 		if err != nil {
 			return nil, err
 		}
+		if v > 07777 {
+			return nil, fmt.Errorf("file mode %s is out of range 0..7777", value)
+		}
+		mode := os.FileMode(v) & os.ModePerm
+		if v&04000 != 0 {
+			mode |= os.ModeSetuid
+		}
+		if v&02000 != 0 {
+			mode |= os.ModeSetgid
+		}
+		if v&01000 != 0 {
+			mode |= os.ModeSticky
+		}
+		return mode, nil
+	})
+
+	RegisterCustomParser((*big.Int)(nil), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", value)
+		}
 		return v, nil
 	})
+
+	RegisterCustomParser((*big.Float)(nil), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, _, err := big.ParseFloat(value, 0, 0, big.ToNearestEven)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+
+	RegisterCustomParser((*mail.Address)(nil), func(value string, tag reflect.StructTag) (interface{}, error) {
+		v, err := mail.ParseAddress(value)
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+
+	RegisterCustomParser(slog.Level(0), func(value string, tag reflect.StructTag) (interface{}, error) {
+		var v slog.Level
+		if err := v.UnmarshalText([]byte(value)); err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+}
+
+func parseTime(value, layout string, loc *time.Location) (time.Time, error) {
+	var unit time.Duration
+	switch layout {
+	case "unix":
+		unit = time.Second
+	case "unixmilli":
+		unit = time.Millisecond
+	case "unixmicro":
+		unit = time.Microsecond
+	case "unixnano":
+		unit = time.Nanosecond
+	default:
+		return time.ParseInLocation(layout, value, loc)
+	}
+
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, nsec := v/int64(time.Second/unit), v%int64(time.Second/unit)*int64(unit)
+	return time.Unix(sec, nsec).In(loc), nil
 }
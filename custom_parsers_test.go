@@ -1,6 +1,14 @@
 package structparse
 
 import (
+	"log/slog"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -22,6 +30,70 @@ func TestCustomParserTimeDuration(t *testing.T) {
 	assertEqual(t, 10*time.Minute, dummy.Duration)
 }
 
+func TestCustomParserTimeDurationExtended(t *testing.T) {
+	var dummy struct {
+		Days        time.Duration `duration:"extended"`
+		Weeks       time.Duration `duration:"extended"`
+		Mixed       time.Duration `duration:"extended"`
+		Negative    time.Duration `duration:"extended"`
+		ISO         time.Duration `duration:"extended"`
+		ISOWeeks    time.Duration `duration:"extended"`
+		BareSec     time.Duration `unit:"s"`
+		BareDays    time.Duration `duration:"extended" unit:"d"`
+		Standard    time.Duration `unit:"ms"`
+		NotEnabled  time.Duration
+		ISOMonths   time.Duration `duration:"extended"`
+		ISOEmptyT   time.Duration `duration:"extended"`
+		ISODayT     time.Duration `duration:"extended"`
+		ISOOverflow time.Duration `duration:"extended"`
+		BareMax     time.Duration `unit:"ns"`
+		BareNaN     time.Duration `unit:"s"`
+		BareInf     time.Duration `unit:"s"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"days":         "7d",
+			"weeks":        "2w",
+			"mixed":        "1w2d3h4m5.5s",
+			"negative":     "-1.5d",
+			"iso":          "P1DT2H30M",
+			"iso-weeks":    "P2W",
+			"bare-sec":     "90",
+			"bare-days":    "2",
+			"standard":     "1m",
+			"not-enabled":  "7d",
+			"iso-months":   "P1M",
+			"iso-empty-t":  "PT",
+			"iso-day-t":    "P1DT",
+			"iso-overflow": "P100000DT1000000H",
+			"bare-max":     "9223372036854775807",
+			"bare-na-n":    "NaN",
+			"bare-inf":     "-Inf",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: not-enabled] [field: NotEnabled] cannot parse as custom type (time Duration): time: unknown unit \"d\" in duration \"7d\", "+
+		"[key: iso-months] [field: ISOMonths] cannot parse as custom type (time Duration): years and months are not supported in duration \"P1M\", "+
+		"[key: iso-empty-t] [field: ISOEmptyT] cannot parse as custom type (time Duration): invalid duration \"PT\", "+
+		"[key: iso-day-t] [field: ISODayT] cannot parse as custom type (time Duration): invalid duration \"P1DT\", "+
+		"[key: iso-overflow] [field: ISOOverflow] cannot parse as custom type (time Duration): duration out of range, "+
+		"[key: bare-max] [field: BareMax] cannot parse as custom type (time Duration): duration out of range, "+
+		"[key: bare-na-n] [field: BareNaN] cannot parse as custom type (time Duration): duration out of range, "+
+		"[key: bare-inf] [field: BareInf] cannot parse as custom type (time Duration): duration out of range", err)
+
+	assertEqual(t, 7*24*time.Hour, dummy.Days)
+	assertEqual(t, 14*24*time.Hour, dummy.Weeks)
+	assertEqual(t, 9*24*time.Hour+3*time.Hour+4*time.Minute+5500*time.Millisecond, dummy.Mixed)
+	assertEqual(t, -36*time.Hour, dummy.Negative)
+	assertEqual(t, 26*time.Hour+30*time.Minute, dummy.ISO)
+	assertEqual(t, 14*24*time.Hour, dummy.ISOWeeks)
+	assertEqual(t, 90*time.Second, dummy.BareSec)
+	assertEqual(t, 48*time.Hour, dummy.BareDays)
+	assertEqual(t, time.Minute, dummy.Standard)
+}
+
 func TestCustomParserTimeTime(t *testing.T) {
 	var dummy struct {
 		T1 time.Time // fallback layout: RFC3339
@@ -46,3 +118,114 @@ func TestCustomParserTimeTime(t *testing.T) {
 	assertEqual(t, "0000-02-10T11:12:13Z", dummy.T2.Format(time.RFC3339))
 	assertEqual(t, "2021-01-01T00:00:00Z", dummy.T3.Format(time.RFC3339))
 }
+
+func TestCustomParserTimeTimeExtended(t *testing.T) {
+	var dummy struct {
+		Zoned     time.Time `layout:"2006-01-02 15:04" tz:"Europe/Berlin"`
+		Unix      time.Time `layout:"unix"`
+		UnixMilli time.Time `layout:"unixmilli" tz:"Europe/Berlin"`
+		Fallback1 time.Time `layout:"RFC3339|2006-01-02|unix"`
+		Fallback2 time.Time `layout:"RFC3339|2006-01-02|unix"`
+		Fallback3 time.Time `layout:"RFC3339|2006-01-02|unix"`
+		NoMatch   time.Time `layout:"RFC3339|unix"`
+		InvalidTZ time.Time `tz:"Invalid/Zone"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"zoned":      "2021-06-01 12:00",
+			"unix":       "1600000000",
+			"unix-milli": "1600000000123",
+			"fallback1":  "2021-01-02T03:04:05Z",
+			"fallback2":  "2021-01-02",
+			"fallback3":  "1600000000",
+			"no-match":   "invalid",
+			"invalid-tz": "2021-01-02T03:04:05Z",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: no-match] [field: NoMatch] cannot parse as custom type (time Time): "+
+		"value \"invalid\" does not match any layout: \"2006-01-02T15:04:05Z07:00\", \"unix\", "+
+		"[key: invalid-tz] [field: InvalidTZ] cannot parse as custom type (time Time): unknown time zone Invalid/Zone", err)
+
+	assertEqual(t, "2021-06-01T12:00:00+02:00", dummy.Zoned.Format(time.RFC3339))
+	assertEqual(t, "2020-09-13T12:26:40Z", dummy.Unix.Format(time.RFC3339))
+	assertEqual(t, "2020-09-13T14:26:40.123+02:00", dummy.UnixMilli.Format(time.RFC3339Nano))
+	assertEqual(t, "2021-01-02T03:04:05Z", dummy.Fallback1.Format(time.RFC3339))
+	assertEqual(t, "2021-01-02T00:00:00Z", dummy.Fallback2.Format(time.RFC3339))
+	assertEqual(t, "2020-09-13T12:26:40Z", dummy.Fallback3.Format(time.RFC3339))
+}
+
+func TestCustomParserStdlib(t *testing.T) {
+	var dummy struct {
+		IP          net.IP
+		IPNet       *net.IPNet
+		IPNetValue  net.IPNet
+		Addr        netip.Addr
+		Prefix      netip.Prefix
+		AddrPort    netip.AddrPort
+		URL         *url.URL
+		HTTPS       *url.URL `scheme:"https"`
+		Regexp      *regexp.Regexp
+		Location    *time.Location
+		FileMode    os.FileMode
+		SpecialMode os.FileMode
+		BigInt      *big.Int
+		BigFloat    *big.Float
+		Mail        mail.Address
+		Level       slog.Level
+		TimePtr     *time.Time
+		InvalidIP   net.IP
+		WrongScheme *url.URL `scheme:"https"`
+		LargeMode   os.FileMode
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"ip":           "10.0.0.1",
+			"ip-net":       "10.0.0.0/8",
+			"ip-net-value": "192.168.0.0/16",
+			"addr":         "::1",
+			"prefix":       "fd00::/8",
+			"addr-port":    "127.0.0.1:8080",
+			"url":          "postgres://localhost/db",
+			"https":        "https://example.com",
+			"regexp":       "^a+$",
+			"location":     "Europe/Berlin",
+			"file-mode":    "0644",
+			"special-mode": "7755",
+			"large-mode":   "17777",
+			"big-int":      "0x1fffffffffffffffffff",
+			"big-float":    "1.5e100",
+			"mail":         "Gopher <gopher@example.com>",
+			"level":        "warn",
+			"time-ptr":     "2021-01-02T03:04:05Z",
+			"invalid-ip":   "invalid",
+			"wrong-scheme": "http://example.com",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: invalid-ip] [field: InvalidIP] cannot parse as custom type (net IP): invalid IP address \"invalid\", "+
+		"[key: wrong-scheme] [field: WrongScheme] cannot parse as custom type (net/url URL): scheme \"http\" is not one of: https, "+
+		"[key: large-mode] [field: LargeMode] cannot parse as custom type (io/fs FileMode): file mode 17777 is out of range 0..7777", err)
+
+	assertEqual(t, "10.0.0.1", dummy.IP)
+	assertEqual(t, "10.0.0.0/8", dummy.IPNet)
+	assertEqual(t, "192.168.0.0/16", &dummy.IPNetValue)
+	assertEqual(t, "::1", dummy.Addr)
+	assertEqual(t, "fd00::/8", dummy.Prefix)
+	assertEqual(t, "127.0.0.1:8080", dummy.AddrPort)
+	assertEqual(t, "postgres://localhost/db", dummy.URL)
+	assertEqual(t, "https://example.com", dummy.HTTPS)
+	assertEqual(t, true, dummy.Regexp.MatchString("aaa"))
+	assertEqual(t, "Europe/Berlin", dummy.Location)
+	assertEqual(t, os.FileMode(0644), dummy.FileMode)
+	assertEqual(t, os.FileMode(0755)|os.ModeSetuid|os.ModeSetgid|os.ModeSticky, dummy.SpecialMode)
+	assertEqual(t, "151115727451828646838271", dummy.BigInt)
+	assertEqual(t, "1.5e+100", dummy.BigFloat)
+	assertEqual(t, "gopher@example.com", dummy.Mail.Address)
+	assertEqual(t, slog.LevelWarn, dummy.Level)
+	assertEqual(t, "2021-01-02T03:04:05Z", dummy.TimePtr.Format(time.RFC3339))
+}
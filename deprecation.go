@@ -0,0 +1,16 @@
+package structparse
+
+import (
+	"log/slog"
+)
+
+// DeprecationLogger returns a Config.OnDeprecated callback that logs a warning
+// to l. If l is nil, slog.Default() is used.
+func DeprecationLogger(l *slog.Logger) func(field Field, alias string) {
+	if l == nil {
+		l = slog.Default()
+	}
+	return func(field Field, alias string) {
+		l.Warn("structparse: deprecated key used", "alias", alias, "key", field.Key, "field", field.Path)
+	}
+}
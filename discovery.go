@@ -0,0 +1,192 @@
+package structparse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// keyPlaceholder is formatted in place of a map key to find the key's position in
+// formatted keys. It is a single lowercase word so that all casings keep it intact.
+const keyPlaceholder = "structparseplaceholder"
+
+func isNestedStruct(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := getCustomParser(typ)
+	return !ok
+}
+
+// isStructMap reports whether typ is a map with struct values that are parsed by
+// discovering their keys. map[K]struct{} is excluded, as it is a set.
+func isStructMap(typ reflect.Type) bool {
+	elem := typ.Elem()
+	if !isNestedStruct(elem) {
+		return false
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.NumField() > 0
+}
+
+// parseStructMap fills map[K]Struct fields by discovering all map keys for which the
+// source contains at least one key of the struct under parentKeys + map key.
+func parseStructMap(cfg Config, field reflect.StructField, fieldValue reflect.Value, parentKeys []string, path string) error {
+	lister, ok := cfg.Src.(KeyLister)
+	if !ok {
+		return &FieldError{
+			Cause:     errors.New("source does not support key discovery"),
+			FieldName: field.Name,
+			FieldPath: path,
+			KeyName:   cfg.KeyFmt.Format(append([]string(nil), parentKeys...)),
+		}
+	}
+
+	names := discoverMapKeys(cfg.KeyFmt, lister.Keys(), parentKeys, field.Type.Elem())
+	if len(names) == 0 {
+		if field.Tag.Get(structTagRequired) == "true" {
+			key := cfg.KeyFmt.Format(append([]string(nil), parentKeys...))
+			return &FieldError{Cause: &MissingKeyError{Key: key, FieldPath: path}, FieldName: field.Name, FieldPath: path, KeyName: key}
+		}
+		return nil
+	}
+
+	typ := field.Type
+	if fieldValue.IsNil() {
+		fieldValue.Set(reflect.MakeMap(typ))
+	}
+
+	var retErr ParseError
+	for _, name := range names {
+		keyVal := reflect.New(typ.Key())
+		if err := assignValue(keyVal, name, field.Tag); err != nil {
+			retErr = append(retErr, &FieldError{Cause: err, FieldName: field.Name, FieldPath: path, KeyName: name})
+			continue
+		}
+
+		elemVal := reflect.New(typ.Elem()).Elem()
+		if elemVal.Kind() == reflect.Ptr {
+			elemVal.Set(reflect.New(typ.Elem().Elem()))
+		}
+
+		elemCfg := cfg
+		elemCfg.KeyFmt = keyFmtLiteral(cfg.KeyFmt, len(parentKeys), name)
+		elemKeys := append(parentKeys[:len(parentKeys):len(parentKeys)], name)
+		elemPath := fmt.Sprintf("%s[%s]", path, name)
+
+		err := parse(elemCfg, reflect.Indirect(elemVal).Addr().Interface(), elemKeys, elemPath)
+		if as := ParseError(nil); errors.As(err, &as) {
+			retErr = append(retErr, as...)
+		} else if err != nil {
+			return err
+		}
+		fieldValue.SetMapIndex(keyVal.Elem(), elemVal)
+	}
+
+	if len(retErr) == 0 {
+		return nil
+	}
+	return retErr
+}
+
+// keyFmtLiteral formats keys with inner but inserts the segment at index as is, so
+// that discovered map keys are not changed by the key formatter's casing.
+func keyFmtLiteral(inner KeyFmt, index int, segment string) KeyFmt {
+	return KeyFmtFunc(func(keys []string) string {
+		if index >= len(keys) || keys[index] != segment {
+			return inner.Format(keys)
+		}
+		keys[index] = keyPlaceholder
+		key := inner.Format(keys)
+		if idx := strings.Index(strings.ToLower(key), keyPlaceholder); idx >= 0 {
+			key = key[:idx] + segment + key[idx+len(keyPlaceholder):]
+		}
+		return key
+	})
+}
+
+// discoverMapKeys returns the map keys found in srcKeys. If a source key matches several
+// leaf fields, the longest leaf wins, so that e.g. PRIMARY_REPLICA_HOST is attributed to
+// the ReplicaHost field of PRIMARY and not to the Host field of PRIMARY_REPLICA.
+func discoverMapKeys(keyFmt KeyFmt, srcKeys []string, parentKeys []string, elemType reflect.Type) []string {
+	type pattern struct{ before, after string }
+	var patterns []pattern
+	for _, leaf := range structKeyPaths(elemType, nil) {
+		keys := append(append(append([]string(nil), parentKeys...), keyPlaceholder), leaf...)
+		key := keyFmt.Format(keys)
+
+		idx := strings.Index(strings.ToLower(key), keyPlaceholder)
+		if idx < 0 {
+			continue
+		}
+		patterns = append(patterns, pattern{before: key[:idx], after: key[idx+len(keyPlaceholder):]})
+	}
+
+	found := make(map[string]struct{})
+	for _, srcKey := range srcKeys {
+		var (
+			name    string
+			matched = -1
+		)
+		for _, p := range patterns {
+			length := len(p.before) + len(p.after)
+			if len(srcKey) <= length || length <= matched {
+				continue
+			}
+			if strings.HasPrefix(srcKey, p.before) && strings.HasSuffix(srcKey, p.after) {
+				name, matched = srcKey[len(p.before):len(srcKey)-len(p.after)], length
+			}
+		}
+		if matched >= 0 {
+			found[name] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// structKeyPaths returns the relative key segments of all leaf fields of typ.
+func structKeyPaths(typ reflect.Type, parentKeys []string) [][]string {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	var paths [][]string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		opts := parseTagOptions(field)
+		if opts.name == "-" || opts.absolute {
+			continue
+		}
+
+		if field.Anonymous {
+			if isNestedStruct(field.Type) {
+				paths = append(paths, structKeyPaths(field.Type, parentKeys)...)
+			}
+			continue
+		}
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+
+		keys := append(parentKeys[:len(parentKeys):len(parentKeys)], opts.segments()...)
+		if isNestedStruct(field.Type) {
+			paths = append(paths, structKeyPaths(field.Type, keys)...)
+			continue
+		}
+		paths = append(paths, keys)
+	}
+	return paths
+}
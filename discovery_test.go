@@ -0,0 +1,72 @@
+package structparse
+
+import (
+	"testing"
+)
+
+func TestParseAt(t *testing.T) {
+	type DBConfig struct {
+		Host string
+		Port int
+	}
+	var primary, replica DBConfig
+
+	cfg := Config{
+		Src: SourceMap{
+			"PRIMARY_HOST":    "primary",
+			"PRIMARY_PORT":    "5432",
+			"DB_REPLICA_HOST": "replica",
+			"DB_REPLICA_PORT": "5433",
+		},
+		KeyFmt: KeyFmtEnv(),
+	}
+	err := ParseAt(cfg, []string{"Primary"}, &primary)
+	assertEqual(t, nil, err)
+	err = ParseAt(cfg, []string{"Db", "Replica"}, &replica)
+	assertEqual(t, nil, err)
+
+	assertEqual(t, DBConfig{Host: "primary", Port: 5432}, primary)
+	assertEqual(t, DBConfig{Host: "replica", Port: 5433}, replica)
+}
+
+func TestParse_StructMap(t *testing.T) {
+	type DBConfig struct {
+		Host        string
+		Port        int    `default:"5432"`
+		ReplicaHost string `optional:"true"`
+	}
+	var dummy struct {
+		Databases map[string]DBConfig
+		Pointers  map[string]*DBConfig `parse:"Db"`
+		Empty     map[string]DBConfig
+		Required  map[string]DBConfig `required:"true"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"DATABASES_PRIMARY_HOST":         "primary",
+			"DATABASES_PRIMARY_PORT":         "1",
+			"DATABASES_PRIMARY_REPLICA_HOST": "primary-replica",
+			"DATABASES_REPLICA_2_HOST":       "replica",
+			"DATABASES_INVALID_PORT":         "invalid",
+			"DB_MAIN_HOST":                   "main",
+		},
+		KeyFmt:       KeyFmtEnv(),
+		Transformers: []Transformer{TransformerDefaultValue()},
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: DATABASES_INVALID_HOST] [field: Databases[INVALID].Host] key not found, "+
+		"[key: DATABASES_INVALID_PORT] [field: Databases[INVALID].Port] cannot parse as int: strconv.ParseInt: parsing \"invalid\": invalid syntax, "+
+		"[key: REQUIRED] [field: Required] key not found", err)
+
+	assertEqual(t, 3, len(dummy.Databases))
+	assertEqual(t, DBConfig{Host: "primary", Port: 1, ReplicaHost: "primary-replica"}, dummy.Databases["PRIMARY"])
+	assertEqual(t, DBConfig{Host: "replica", Port: 5432}, dummy.Databases["REPLICA_2"])
+	assertEqual(t, DBConfig{Host: "main", Port: 5432}, *dummy.Pointers["MAIN"])
+	assertEqual(t, true, dummy.Empty == nil)
+
+	// sources that cannot list keys do not support discovery
+	cfg.Src = SourceNil()
+	err = Parse(cfg, &struct{ Databases map[string]DBConfig }{})
+	assertEqual(t, "[key: DATABASES] [field: Databases] source does not support key discovery", err)
+}
@@ -0,0 +1,194 @@
+package structparse
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	structTagDuration = "duration"
+
+	durationExtended = "extended"
+)
+
+var (
+	extendedDurationUnits = map[string]time.Duration{
+		"ns": time.Nanosecond,
+		"us": time.Microsecond,
+		"µs": time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"w":  7 * 24 * time.Hour,
+	}
+
+	errDurationOverflow = errors.New("duration out of range")
+)
+
+// parseDuration parses value with time.ParseDuration. With duration:"extended", days and
+// weeks (7d, 2w1d) as well as ISO-8601 durations (P1DT2H) are accepted. With a unit tag,
+// e.g. unit:"s", bare numbers are interpreted in that unit.
+func parseDuration(value string, tag reflect.StructTag) (time.Duration, error) {
+	extended := tag.Get(structTagDuration) == durationExtended
+
+	if unit, ok := tag.Lookup(structTagUnit); ok {
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			multiplier, ok := extendedDurationUnits[unit]
+			if !ok || (!extended && (unit == "d" || unit == "w")) {
+				return 0, fmt.Errorf("unsupported unit %s", unit)
+			}
+			return scaleDuration(number, multiplier)
+		}
+	}
+
+	if !extended {
+		return time.ParseDuration(value)
+	}
+	if strings.HasPrefix(value, "P") || strings.HasPrefix(value, "-P") {
+		return parseISODuration(value)
+	}
+	return parseExtendedDuration(value)
+}
+
+func scaleDuration(number float64, unit time.Duration) (time.Duration, error) {
+	d := number * float64(unit)
+	// float64(math.MaxInt64) rounds up to 2^63, which does not fit into an int64
+	if math.IsNaN(d) || d >= float64(math.MaxInt64) || d < math.MinInt64 {
+		return 0, errDurationOverflow
+	}
+	return time.Duration(d), nil
+}
+
+func parseExtendedDuration(value string) (time.Duration, error) {
+	s := value
+	negative := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	if s == "0" {
+		return 0, nil
+	}
+	if len(s) == 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var total time.Duration
+	for len(s) > 0 {
+		i := 0
+		for i < len(s) && (s[i] == '.' || ('0' <= s[i] && s[i] <= '9')) {
+			i++
+		}
+		j := i
+		for j < len(s) && s[j] != '.' && (s[j] < '0' || s[j] > '9') {
+			j++
+		}
+
+		number, err := strconv.ParseFloat(s[:i], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		unit, ok := extendedDurationUnits[s[i:j]]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q in duration %q", s[i:j], value)
+		}
+		d, err := scaleDuration(number, unit)
+		if err != nil {
+			return 0, err
+		}
+		if total+d < total {
+			return 0, errDurationOverflow
+		}
+		total += d
+		s = s[j:]
+	}
+
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
+
+// parseISODuration parses ISO-8601 durations in the format PnWnDTnHnMnS. Years and
+// months are rejected since their length is not fixed.
+func parseISODuration(value string) (time.Duration, error) {
+	s := value
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "P")
+	if len(s) == 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var (
+		total     time.Duration
+		inTime    bool
+		timeEmpty bool
+	)
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if inTime {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			inTime, timeEmpty = true, true
+			s = s[1:]
+			continue
+		}
+
+		i := 0
+		for i < len(s) && (s[i] == '.' || s[i] == ',' || ('0' <= s[i] && s[i] <= '9')) {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		number, err := strconv.ParseFloat(strings.Replace(s[:i], ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+
+		var unit time.Duration
+		switch designator := s[i]; {
+		case !inTime && designator == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && designator == 'D':
+			unit = 24 * time.Hour
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, fmt.Errorf("years and months are not supported in duration %q", value)
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+
+		d, err := scaleDuration(number, unit)
+		if err != nil {
+			return 0, err
+		}
+		if total+d < total {
+			return 0, errDurationOverflow
+		}
+		total += d
+		timeEmpty = false
+		s = s[i+1:]
+	}
+	// the time designator must be followed by at least one component
+	if timeEmpty {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	if negative {
+		total = -total
+	}
+	return total, nil
+}
@@ -0,0 +1,104 @@
+package structparse
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type enum struct {
+	names  []string
+	values map[string]reflect.Value
+}
+
+var (
+	enumsMu sync.Mutex
+	enums   = make(map[string]*enum)
+)
+
+// RegisterEnum registers a name to value table for the map's value type, e.g.
+// map[string]Mode{"debug": ModeDebug, "release": ModeRelease}. Names are matched
+// case-insensitively.
+func RegisterEnum(values interface{}) {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		panic("enum values have to be a map with string keys")
+	}
+
+	names := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		names = append(names, k.String())
+	}
+	sort.Strings(names)
+
+	e := &enum{names: names, values: make(map[string]reflect.Value)}
+	for _, name := range names {
+		e.add(name, v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())))
+	}
+	registerEnum(v.Type().Elem(), e)
+}
+
+// RegisterEnumStringer registers an enum by using the String method of each value as
+// its name. All values have to be of the same type.
+func RegisterEnumStringer(values ...fmt.Stringer) {
+	if len(values) == 0 {
+		panic("enum values are missing")
+	}
+
+	typ := reflect.TypeOf(values[0])
+	e := &enum{values: make(map[string]reflect.Value)}
+	for _, value := range values {
+		if reflect.TypeOf(value) != typ {
+			panic(fmt.Sprintf("enum values have different types: %s and %s", typ, reflect.TypeOf(value)))
+		}
+		e.names = append(e.names, value.String())
+		e.add(value.String(), reflect.ValueOf(value))
+	}
+	registerEnum(typ, e)
+}
+
+// EnumValues returns the names registered for the enum type of typ, e.g. to list them
+// in generated documentation.
+func EnumValues(typ interface{}) ([]string, bool) {
+	e, ok := getEnum(reflect.TypeOf(typ))
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), e.names...), true
+}
+
+func (e *enum) add(name string, value reflect.Value) {
+	key := strings.ToLower(name)
+	if _, ok := e.values[key]; ok {
+		panic(fmt.Sprintf("duplicate enum name: %s", name))
+	}
+	e.values[key] = value
+}
+
+func (e *enum) parse(src string) (reflect.Value, error) {
+	if value, ok := e.values[strings.ToLower(src)]; ok {
+		return value, nil
+	}
+	return reflect.Value{}, fmt.Errorf("%q is not one of: %s", src, strings.Join(e.names, ", "))
+}
+
+func registerEnum(typ reflect.Type, e *enum) {
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+
+	name := getCustomParserName(typ)
+	if _, ok := enums[name]; ok {
+		panic(fmt.Sprintf("enum already registered for: %s", name))
+	}
+	enums[name] = e
+}
+
+func getEnum(typ reflect.Type) (*enum, bool) {
+	enumsMu.Lock()
+	defer enumsMu.Unlock()
+
+	e, ok := enums[getCustomParserName(typ)]
+	return e, ok
+}
@@ -0,0 +1,79 @@
+package structparse
+
+import (
+	"testing"
+)
+
+type testMode int
+
+const (
+	testModeDebug testMode = iota
+	testModeRelease
+)
+
+type testLevel int
+
+const (
+	testLevelInfo testLevel = iota
+	testLevelWarn
+)
+
+func (l testLevel) String() string {
+	switch l {
+	case testLevelInfo:
+		return "info"
+	case testLevelWarn:
+		return "warn"
+	}
+	return "unknown"
+}
+
+func TestRegisterEnum(t *testing.T) {
+	RegisterEnum(map[string]testMode{"debug": testModeDebug, "release": testModeRelease})
+	RegisterEnumStringer(testLevelInfo, testLevelWarn)
+
+	var dummy struct {
+		Mode    testMode
+		Level   testLevel
+		Levels  []testLevel
+		Ptr     *testMode
+		Invalid testMode
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"mode":    "Release",
+			"level":   "WARN",
+			"levels":  "info,warn",
+			"ptr":     "release",
+			"invalid": "trace",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: invalid] [field: Invalid] cannot parse as enum (github.com/tim-oster/structparse testMode): "+
+		"\"trace\" is not one of: debug, release", err)
+
+	assertEqual(t, testModeRelease, dummy.Mode)
+	assertEqual(t, testLevelWarn, dummy.Level)
+	assertEqual(t, []testLevel{testLevelInfo, testLevelWarn}, dummy.Levels)
+	assertEqual(t, testModeRelease, *dummy.Ptr)
+
+	values, ok := EnumValues(testModeDebug)
+	assertEqual(t, true, ok)
+	assertEqual(t, []string{"debug", "release"}, values)
+	values, ok = EnumValues(testLevelInfo)
+	assertEqual(t, true, ok)
+	assertEqual(t, []string{"info", "warn"}, values)
+	_, ok = EnumValues(0)
+	assertEqual(t, false, ok)
+
+	// already registered
+	func() {
+		defer func() {
+			p := recover()
+			assertEqual(t, "enum already registered for: github.com/tim-oster/structparse/testMode", p)
+		}()
+		RegisterEnum(map[string]testMode{"other": 2})
+	}()
+}
@@ -0,0 +1,143 @@
+package structparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const redacted = "[redacted]"
+
+// MissingKeyError is the cause of a FieldError if a key does not exist in the source
+// and the field is not optional.
+type MissingKeyError struct {
+	Key       string
+	FieldPath string
+}
+
+func (err *MissingKeyError) Unwrap() error {
+	return ErrSourceKeyNotFound
+}
+
+func (err *MissingKeyError) Error() string {
+	return ErrSourceKeyNotFound.Error()
+}
+
+// InvalidValueError is the cause of a FieldError if a value cannot be assigned to
+// its field. Value is empty for fields tagged with secret:"true".
+type InvalidValueError struct {
+	Cause     error
+	Key       string
+	FieldPath string
+	Type      reflect.Type
+	Value     string
+}
+
+func newInvalidValueError(cause error, key, path string, field reflect.StructField, value string) *InvalidValueError {
+	if field.Tag.Get(structTagSecret) == "true" {
+		cause = redactError(cause, value)
+		value = ""
+	}
+	return &InvalidValueError{
+		Cause:     cause,
+		Key:       key,
+		FieldPath: path,
+		Type:      field.Type,
+		Value:     value,
+	}
+}
+
+func (err *InvalidValueError) Unwrap() error {
+	return err.Cause
+}
+
+func (err *InvalidValueError) Error() string {
+	return err.Cause.Error()
+}
+
+// redactError removes value from the messages of err and its causes. AssignError and
+// strconv.NumError keep their type, all other errors are wrapped.
+func redactError(err error, value string) error {
+	switch e := err.(type) {
+	case *AssignError:
+		return &AssignError{Cause: redactError(e.Cause, value), Msg: redactString(e.Msg, value)}
+	case *strconv.NumError:
+		return &strconv.NumError{Func: e.Func, Num: redacted, Err: e.Err}
+	}
+	return &redactedError{cause: err, msg: redactString(err.Error(), value)}
+}
+
+func redactString(s, value string) string {
+	if len(value) == 0 {
+		return s
+	}
+	quoted := strconv.Quote(value)
+	s = strings.ReplaceAll(s, quoted[1:len(quoted)-1], redacted)
+	return strings.ReplaceAll(s, value, redacted)
+}
+
+type redactedError struct {
+	cause error
+	msg   string
+}
+
+func (err *redactedError) Unwrap() error {
+	return err.cause
+}
+
+func (err *redactedError) Error() string {
+	return err.msg
+}
+
+// UnsupportedTypeError is returned for field types that cannot be parsed.
+type UnsupportedTypeError struct {
+	Type     reflect.Type
+	Embedded bool
+}
+
+func (err *UnsupportedTypeError) Error() string {
+	if err.Embedded {
+		return fmt.Sprintf("unsupported anonymus type %s", err.Type.Kind())
+	}
+	return fmt.Sprintf("unsupported dst type %s", err.Type.Kind())
+}
+
+// ValidationError is returned if a parsed value violates a constraint defined on its field.
+type ValidationError struct {
+	Key       string
+	FieldPath string
+	Rule      string
+	Msg       string
+}
+
+func (err *ValidationError) Error() string {
+	return err.Msg
+}
+
+// SourceError wraps errors returned by Source.Get other than ErrSourceKeyNotFound.
+type SourceError struct {
+	Cause error
+	Key   string
+}
+
+func (err *SourceError) Unwrap() error {
+	return err.Cause
+}
+
+func (err *SourceError) Error() string {
+	return fmt.Sprintf("source error: %s", err.Cause)
+}
+
+type AssignError struct {
+	Cause error
+	Msg   string
+}
+
+func (err *AssignError) Unwrap() error {
+	return err.Cause
+}
+
+func (err *AssignError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Msg, err.Cause)
+}
@@ -0,0 +1,103 @@
+package structparse
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	var dummy struct {
+		Missing     string
+		Invalid     int
+		Secret      int `secret:"true"`
+		Unsupported chan int
+		Broken      string
+	}
+
+	cfg := Config{
+		Src: SourceFunc(func(key string) (string, error) {
+			switch key {
+			case "invalid", "secret":
+				return "abc", nil
+			case "unsupported":
+				return "", nil
+			case "broken":
+				return "", errors.New("connection refused")
+			}
+			return "", ErrSourceKeyNotFound
+		}),
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+
+	var parseErr ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected parse error but got '%s'", err)
+	}
+	assertEqual(t, 5, len(parseErr))
+
+	var missingErr *MissingKeyError
+	if assertEqual(t, true, errors.As(parseErr[0], &missingErr)) {
+		assertEqual(t, "missing", missingErr.Key)
+		assertEqual(t, "Missing", missingErr.FieldPath)
+	}
+
+	var invalidErr *InvalidValueError
+	if assertEqual(t, true, errors.As(parseErr[1], &invalidErr)) {
+		assertEqual(t, "invalid", invalidErr.Key)
+		assertEqual(t, "Invalid", invalidErr.FieldPath)
+		assertEqual(t, "int", invalidErr.Type)
+		assertEqual(t, "abc", invalidErr.Value)
+	}
+	if assertEqual(t, true, errors.As(parseErr[2], &invalidErr)) {
+		assertEqual(t, "", invalidErr.Value)
+	}
+
+	var unsupportedErr *UnsupportedTypeError
+	if assertEqual(t, true, errors.As(parseErr[3], &unsupportedErr)) {
+		assertEqual(t, "chan int", unsupportedErr.Type)
+	}
+	assertEqual(t, "[key: unsupported] [field: Unsupported] unsupported dst type chan", parseErr[3])
+
+	var sourceErr *SourceError
+	if assertEqual(t, true, errors.As(parseErr[4], &sourceErr)) {
+		assertEqual(t, "broken", sourceErr.Key)
+	}
+	assertEqual(t, "[key: broken] [field: Broken] source error: connection refused", parseErr[4])
+}
+
+func TestErrors_SecretRedacted(t *testing.T) {
+	var dummy struct {
+		Int      int           `secret:"true"`
+		Duration time.Duration `secret:"true"`
+		Port     int           `secret:"true" range:"1..100"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"int":      "hunter2",
+			"duration": "hunter2",
+			"port":     "2000",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: int] [field: Int] cannot parse as int: strconv.ParseInt: parsing \"[redacted]\": invalid syntax, "+
+		"[key: duration] [field: Duration] cannot parse as custom type (time Duration): time: invalid duration \"[redacted]\", "+
+		"[key: port] [field: Port] value [redacted] is out of range 1..100", err)
+
+	data, jsonErr := json.Marshal(err)
+	assertEqual(t, nil, jsonErr)
+	assertEqual(t, false, strings.Contains(err.Error(), "hunter2"))
+	assertEqual(t, false, strings.Contains(string(data), "hunter2"))
+	assertEqual(t, false, strings.Contains(string(data), "2000"))
+
+	var numErr *strconv.NumError
+	if assertEqual(t, true, errors.As(err, &numErr)) {
+		assertEqual(t, strconv.ErrSyntax, numErr.Err)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/tim-oster/structparse
 
-go 1.14
+go 1.21
@@ -0,0 +1,127 @@
+package structparse
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	structTagDiscriminator = "discriminator"
+
+	defaultDiscriminator = "Driver"
+)
+
+var (
+	implementationsMu sync.Mutex
+	implementations   = make(map[reflect.Type]map[string]reflect.Type)
+)
+
+// RegisterImplementation registers impl as the concrete type of interface fields of type
+// iface, which is selected if the field's discriminator key has the given value. iface has
+// to be passed as a pointer to the interface, e.g. (*StorageConfig)(nil). impl may be a
+// struct or a pointer to a struct.
+//
+// The discriminator key is the field's key extended by the discriminator tag, which
+// defaults to "Driver", e.g. STORAGE_DRIVER=s3.
+func RegisterImplementation(iface interface{}, discriminator string, impl interface{}) {
+	implementationsMu.Lock()
+	defer implementationsMu.Unlock()
+
+	ifaceType := reflect.TypeOf(iface)
+	if ifaceType == nil || ifaceType.Kind() != reflect.Ptr || ifaceType.Elem().Kind() != reflect.Interface {
+		panic("iface has to be a pointer to an interface")
+	}
+	ifaceType = ifaceType.Elem()
+
+	implType := reflect.TypeOf(impl)
+	if implType == nil || !isNestedStruct(implType) {
+		panic(fmt.Sprintf("impl has to be a struct or pointer to a struct: %s", implType))
+	}
+	if !implType.Implements(ifaceType) {
+		panic(fmt.Sprintf("%s does not implement %s", implType, ifaceType))
+	}
+
+	impls, ok := implementations[ifaceType]
+	if !ok {
+		impls = make(map[string]reflect.Type)
+		implementations[ifaceType] = impls
+	}
+	if _, ok := impls[discriminator]; ok {
+		panic(fmt.Sprintf("implementation already registered for: %s %s", ifaceType, discriminator))
+	}
+	impls[discriminator] = implType
+}
+
+func hasImplementations(typ reflect.Type) bool {
+	implementationsMu.Lock()
+	defer implementationsMu.Unlock()
+
+	_, ok := implementations[typ]
+	return ok
+}
+
+func getImplementation(typ reflect.Type, discriminator string) (reflect.Type, []string) {
+	implementationsMu.Lock()
+	defer implementationsMu.Unlock()
+
+	impls := implementations[typ]
+	if implType, ok := impls[discriminator]; ok {
+		return implType, nil
+	}
+
+	allowed := make([]string, 0, len(impls))
+	for k := range impls {
+		allowed = append(allowed, k)
+	}
+	sort.Strings(allowed)
+	return nil, allowed
+}
+
+func parseImplementation(cfg Config, field reflect.StructField, fieldValue reflect.Value, parentKeys []string, path string) error {
+	discriminator := field.Tag.Get(structTagDiscriminator)
+	if len(discriminator) == 0 {
+		discriminator = defaultDiscriminator
+	}
+
+	key := cfg.KeyFmt.Format(append(append([]string(nil), parentKeys...), discriminator))
+	desc := Field{
+		Key:         key,
+		StructField: field,
+		Path:        path,
+		ParentKeys:  append([]string(nil), parentKeys...),
+		Type:        reflect.TypeOf(""),
+		cache:       cfg.cache,
+	}
+	value, ok, err := readValue(cfg, desc)
+	if !ok {
+		return err
+	}
+
+	implType, allowed := getImplementation(field.Type, value)
+	if implType == nil {
+		return &FieldError{
+			Cause: newInvalidValueError(
+				fmt.Errorf("unknown implementation %q, expected one of: %s", value, strings.Join(allowed, ", ")),
+				key, path, field, value,
+			),
+			FieldName: field.Name,
+			FieldPath: path,
+			KeyName:   key,
+		}
+	}
+
+	var target, impl reflect.Value
+	if implType.Kind() == reflect.Ptr {
+		target = reflect.New(implType.Elem())
+		impl = target
+	} else {
+		target = reflect.New(implType)
+		impl = target.Elem()
+	}
+	err = parse(cfg, target.Interface(), parentKeys, path)
+	fieldValue.Set(impl)
+	return err
+}
@@ -0,0 +1,82 @@
+package structparse
+
+import (
+	"testing"
+)
+
+type testStorage interface {
+	storage()
+}
+
+type testS3Storage struct {
+	Bucket string
+}
+
+func (testS3Storage) storage() {}
+
+type testFSStorage struct {
+	Path string
+}
+
+func (*testFSStorage) storage() {}
+
+func TestParse_Implementation(t *testing.T) {
+	RegisterImplementation((*testStorage)(nil), "s3", testS3Storage{})
+	RegisterImplementation((*testStorage)(nil), "fs", (*testFSStorage)(nil))
+
+	var dummy struct {
+		Storage  testStorage
+		Backup   testStorage `discriminator:"Type"`
+		Unknown  testStorage
+		Missing  testStorage
+		Optional testStorage `optional:"true"`
+		Default  testStorage `default:"fs"`
+		Aliased  testStorage `aliases:"LEGACY_DRIVER"`
+		Upper    testStorage `lower:"true"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"STORAGE_DRIVER": "s3",
+			"STORAGE_BUCKET": "bucket",
+			"BACKUP_TYPE":    "fs",
+			"BACKUP_PATH":    "/backup",
+			"UNKNOWN_DRIVER": "ftp",
+			"DEFAULT_PATH":   "/default",
+			"LEGACY_DRIVER":  "fs",
+			"ALIASED_PATH":   "/aliased",
+			"UPPER_DRIVER":   "S3",
+			"UPPER_BUCKET":   "upper",
+		},
+		KeyFmt:       KeyFmtEnv(),
+		Transformers: []Transformer{TransformerDefaultValue(), TransformerCaseFold()},
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: UNKNOWN_DRIVER] [field: Unknown] unknown implementation \"ftp\", expected one of: fs, s3, "+
+		"[key: MISSING_DRIVER] [field: Missing] key not found", err)
+
+	assertEqual(t, testS3Storage{Bucket: "bucket"}, dummy.Storage)
+	assertEqual(t, &testFSStorage{Path: "/backup"}, dummy.Backup)
+	assertEqual(t, nil, dummy.Optional)
+	assertEqual(t, &testFSStorage{Path: "/default"}, dummy.Default)
+	assertEqual(t, &testFSStorage{Path: "/aliased"}, dummy.Aliased)
+	assertEqual(t, testS3Storage{Bucket: "upper"}, dummy.Upper)
+
+	// already registered
+	func() {
+		defer func() {
+			p := recover()
+			assertEqual(t, "implementation already registered for: structparse.testStorage s3", p)
+		}()
+		RegisterImplementation((*testStorage)(nil), "s3", testS3Storage{})
+	}()
+
+	// does not implement the interface
+	func() {
+		defer func() {
+			p := recover()
+			assertEqual(t, "structparse.testFSStorage does not implement structparse.testStorage", p)
+		}()
+		RegisterImplementation((*testStorage)(nil), "other", testFSStorage{})
+	}()
+}
@@ -0,0 +1,132 @@
+package structparse
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	structTagInfer = "infer"
+)
+
+// InferPolicy is a set of types that are inferred for values of interface{} fields.
+// Values that match none of them are assigned as string.
+type InferPolicy uint8
+
+const (
+	// InferBool infers true and false, case-insensitively, as bool.
+	InferBool InferPolicy = 1 << iota
+	// InferInt infers base 10 integers as int64.
+	InferInt
+	// InferFloat infers decimal numbers as float64.
+	InferFloat
+	// InferSlice infers values containing the delimiter as []interface{}, whose elements
+	// are inferred with the remaining policy.
+	InferSlice
+	// InferNone disables inference, all values are assigned as string.
+	InferNone
+
+	InferDefault = InferBool | InferInt | InferFloat
+)
+
+var inferPolicyNames = []struct {
+	policy InferPolicy
+	name   string
+}{
+	{InferBool, "bool"},
+	{InferInt, "int"},
+	{InferFloat, "float"},
+	{InferSlice, "slice"},
+	{InferNone, "none"},
+}
+
+// String returns the policy in the format of the infer tag, e.g. bool,int,float.
+func (p InferPolicy) String() string {
+	var names []string
+	for _, n := range inferPolicyNames {
+		if p&n.policy != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+func parseInferPolicy(tag reflect.StructTag) (InferPolicy, error) {
+	value, ok := tag.Lookup(structTagInfer)
+	if !ok {
+		return InferDefault, nil
+	}
+
+	var p InferPolicy
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, n := range inferPolicyNames {
+			if n.name == name {
+				p |= n.policy
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unsupported infer policy %s", name)
+		}
+	}
+	return p, nil
+}
+
+// inferValue converts src into bool, int64, float64, []interface{} or string according
+// to the infer tag.
+func inferValue(src string, tag reflect.StructTag) (interface{}, error) {
+	p, err := parseInferPolicy(tag)
+	if err != nil {
+		return nil, err
+	}
+	if p&InferNone != 0 {
+		return src, nil
+	}
+
+	if p&InferSlice != 0 {
+		delimiter := tag.Get(structTagDelimiter)
+		if len(delimiter) == 0 {
+			delimiter = ","
+		}
+		if strings.Contains(src, delimiter) {
+			parts, err := splitSliceElements(src, delimiter, tag)
+			if err != nil {
+				return nil, err
+			}
+			values := make([]interface{}, len(parts))
+			for i, part := range parts {
+				values[i] = inferScalar(part, p)
+			}
+			return values, nil
+		}
+	}
+	return inferScalar(src, p), nil
+}
+
+func inferScalar(src string, p InferPolicy) interface{} {
+	if p&InferInt != 0 {
+		if v, err := strconv.ParseInt(src, 10, 64); err == nil {
+			return v
+		}
+	}
+	// only accept actual numbers, ParseFloat also accepts values like inf or nan
+	if p&InferFloat != 0 && strings.IndexFunc(src, unicode.IsDigit) >= 0 {
+		if v, err := strconv.ParseFloat(src, 64); err == nil {
+			return v
+		}
+	}
+	if p&InferBool != 0 {
+		if strings.EqualFold(src, "true") {
+			return true
+		}
+		if strings.EqualFold(src, "false") {
+			return false
+		}
+	}
+	return src
+}
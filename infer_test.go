@@ -0,0 +1,83 @@
+package structparse
+
+import (
+	"testing"
+)
+
+func TestParse_Complex(t *testing.T) {
+	var dummy struct {
+		Complex64  complex64
+		Complex128 complex128
+		Invalid    complex128
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"complex64":  "1+2i",
+			"complex128": "(3.5-1i)",
+			"invalid":    "i+1",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: invalid] [field: Invalid] cannot parse as complex: strconv.ParseComplex: parsing \"i+1\": invalid syntax", err)
+
+	assertEqual(t, complex64(1+2i), dummy.Complex64)
+	assertEqual(t, 3.5-1i, dummy.Complex128)
+}
+
+func TestParse_Infer(t *testing.T) {
+	var dummy struct {
+		Bool    interface{}
+		Int     interface{}
+		Float   interface{}
+		String  interface{}
+		NaN     interface{}
+		NoSlice interface{}
+		Slice   interface{} `infer:"slice,int"`
+		Strings interface{} `infer:"none"`
+		Map     map[string]interface{}
+		Unknown interface{} `infer:"other"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"bool":     "TRUE",
+			"int":      "-42",
+			"float":    "1.5",
+			"string":   "value",
+			"na-n":     "nan",
+			"no-slice": "a,b",
+			"slice":    "1,b",
+			"strings":  "42",
+			"map":      "a=1&b=x",
+			"unknown":  "1",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: unknown] [field: Unknown] cannot infer value: unsupported infer policy other", err)
+
+	assertEqual(t, true, dummy.Bool)
+	assertEqual(t, int64(-42), dummy.Int)
+	assertEqual(t, 1.5, dummy.Float)
+	assertEqual(t, "value", dummy.String)
+	assertEqual(t, "nan", dummy.NaN)
+	assertEqual(t, "a,b", dummy.NoSlice)
+	assertEqual(t, []interface{}{int64(1), "b"}, dummy.Slice)
+	assertEqual(t, "42", dummy.Strings)
+	assertEqual(t, map[string]interface{}{"a": int64(1), "b": "x"}, dummy.Map)
+
+	_, isInt := dummy.Int.(int64)
+	assertEqual(t, true, isInt)
+
+	// configure policy for all fields
+	cfg.InferPolicy = InferSlice | InferInt
+	cfg.Src = SourceMap{"no-slice": "1,2", "bool": "true"}
+	cfg.IgnoreMissing = true
+	err = Parse(cfg, &dummy)
+	assertEqual(t, nil, err)
+	assertEqual(t, []interface{}{int64(1), int64(2)}, dummy.NoSlice)
+	assertEqual(t, "true", dummy.Bool)
+	assertEqual(t, "int,slice", cfg.InferPolicy)
+}
@@ -0,0 +1,162 @@
+package structparse
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	structTagThousands = "thousands"
+	structTagDecimal   = "decimal"
+	structTagRange     = "range"
+	structTagPercent   = "percent"
+)
+
+// normalizeNumber removes the separator set by the thousands tag, e.g. thousands:","
+// for 1,000,000. The separator is only accepted between groups of three digits. For
+// floats, the separator set by the decimal tag is replaced by ".".
+func normalizeNumber(src string, tag reflect.StructTag, float bool) (string, error) {
+	decimal := "."
+	if sep, ok := tag.Lookup(structTagDecimal); ok && len(sep) > 0 && float {
+		decimal = sep
+	}
+	if sep, ok := tag.Lookup(structTagThousands); ok && len(sep) > 0 && strings.Contains(src, sep) {
+		integer, fraction := src, ""
+		if i := strings.Index(src, decimal); i >= 0 && float {
+			integer, fraction = src[:i], src[i:]
+		}
+		groups := strings.Split(integer, sep)
+		for i, group := range groups {
+			if i == 0 {
+				group = strings.TrimLeft(group, "+-")
+			}
+			if (i == 0 && (len(group) == 0 || len(group) > 3)) || (i > 0 && len(group) != 3) {
+				return "", fmt.Errorf("invalid digit grouping in %q", src)
+			}
+		}
+		src = strings.Join(groups, "") + fraction
+	}
+	if decimal != "." {
+		src = strings.Replace(src, decimal, ".", 1)
+	}
+	return src, nil
+}
+
+// parseFloat is like strconv.ParseFloat but also accepts percentages if the percent tag
+// is set, e.g. 50% is 0.5 for percent:"true".
+func parseFloat(src string, bits int, tag reflect.StructTag) (float64, error) {
+	if tag.Get(structTagPercent) != "true" || !strings.HasSuffix(src, "%") {
+		return strconv.ParseFloat(src, bits)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(src, "%")), 64)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strconv.FormatFloat(f/100, 'g', -1, 64), bits)
+}
+
+// checkFloatRange validates f like checkRange. f is formatted with its own precision, so
+// that float32 values compare by their shortest representation. NaN and ±Inf are never
+// within a range.
+func checkFloatRange(f float64, bits int, tag reflect.StructTag) error {
+	r, ok := tag.Lookup(structTagRange)
+	if !ok {
+		return nil
+	}
+	display := strconv.FormatFloat(f, 'g', -1, bits)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return &ValidationError{
+			Rule: structTagRange,
+			Msg:  fmt.Sprintf("value %s is out of range %s", display, r),
+		}
+	}
+	v, ok := new(big.Rat).SetString(display)
+	if !ok {
+		return fmt.Errorf("cannot compare %s to range", display)
+	}
+	return checkRange(v, display, tag)
+}
+
+// checkRange validates v against the range tag in the format min..max. Both bounds are
+// inclusive and optional, e.g. range:"1..65535" or range:"0..".
+func checkRange(v *big.Rat, display string, tag reflect.StructTag) error {
+	return checkRangeBounds(v, display, tag, func(bound string) (*big.Rat, bool) {
+		return new(big.Rat).SetString(bound)
+	})
+}
+
+// checkCustomRange validates integer and float values returned by custom parsers against
+// the range tag. The bounds are parsed with the same parser, e.g. range:"1s..1h" for
+// time.Duration.
+func checkCustomRange(v reflect.Value, p Parser, tag reflect.StructTag) error {
+	r, ok := tag.Lookup(structTagRange)
+	if !ok {
+		return nil
+	}
+	display := fmt.Sprint(v.Interface())
+	if k := v.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+		if math.IsNaN(v.Float()) || math.IsInf(v.Float(), 0) {
+			return &ValidationError{
+				Rule: structTagRange,
+				Msg:  fmt.Sprintf("value %s is out of range %s", display, r),
+			}
+		}
+	}
+	rat, ok := numberRat(v)
+	if !ok {
+		return fmt.Errorf("range is not supported for type %s", v.Type())
+	}
+	return checkRangeBounds(rat, display, tag, func(bound string) (*big.Rat, bool) {
+		b, err := p(bound, tag)
+		if err != nil {
+			return nil, false
+		}
+		return numberRat(convertCustomValue(reflect.ValueOf(b), v.Type()))
+	})
+}
+
+// numberRat converts integer and finite float values to a big.Rat.
+func numberRat(v reflect.Value) (*big.Rat, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(v.Uint())), true
+	case reflect.Float32, reflect.Float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	}
+	return nil, false
+}
+
+func checkRangeBounds(v *big.Rat, display string, tag reflect.StructTag, parseBound func(string) (*big.Rat, bool)) error {
+	r, ok := tag.Lookup(structTagRange)
+	if !ok {
+		return nil
+	}
+	bounds := strings.SplitN(r, "..", 2)
+	if len(bounds) != 2 {
+		return fmt.Errorf("invalid range tag %q", r)
+	}
+
+	for i, bound := range bounds {
+		bound = strings.TrimSpace(bound)
+		if len(bound) == 0 {
+			continue
+		}
+		b, ok := parseBound(bound)
+		if !ok {
+			return fmt.Errorf("invalid range tag %q", r)
+		}
+		if (i == 0 && v.Cmp(b) < 0) || (i == 1 && v.Cmp(b) > 0) {
+			return &ValidationError{
+				Rule: structTagRange,
+				Msg:  fmt.Sprintf("value %s is out of range %s", display, r),
+			}
+		}
+	}
+	return nil
+}
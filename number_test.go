@@ -0,0 +1,112 @@
+package structparse
+
+import (
+	"log/slog"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestParseNumbers(t *testing.T) {
+	var dummy struct {
+		Underscore   int
+		Thousands    int64   `thousands:","`
+		Locale       float64 `thousands:"." decimal:","`
+		Percent      float64 `percent:"true"`
+		Port         uint16  `range:"1..65535"`
+		Min          int     `range:"0.."`
+		Ratio        float32 `range:"0..1" percent:"true"`
+		Tenth        float32 `range:"0..0.1"`
+		PortTooLow   uint16  `range:"1..65535"`
+		Negative     int     `range:"0.."`
+		RatioTooHigh float64 `range:"0..1"`
+		NoPercent    float64
+		NaN          float64 `range:"0..1"`
+		Inf          float32 `range:"0.."`
+		ShortGroup   int     `thousands:","`
+		SingleDigits int     `thousands:","`
+		LongGroup    float64 `thousands:","`
+		Negative1000 int     `thousands:","`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"underscore":     "1_000",
+			"thousands":      "1,000,000",
+			"locale":         "1.234,5",
+			"percent":        "50%",
+			"port":           "8080",
+			"min":            "0",
+			"ratio":          "25%",
+			"port-too-low":   "0",
+			"negative":       "-1",
+			"ratio-too-high": "1.5",
+			"tenth":          "0.1",
+			"no-percent":     "50%",
+			"na-n":           "NaN",
+			"inf":            "+Inf",
+			"short-group":    "1,00",
+			"single-digits":  "1,0,0",
+			"long-group":     "1234,567.5",
+			"negative1000":   "-1,000",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: port-too-low] [field: PortTooLow] value 0 is out of range 1..65535, "+
+		"[key: negative] [field: Negative] value -1 is out of range 0.., "+
+		"[key: ratio-too-high] [field: RatioTooHigh] value 1.5 is out of range 0..1, "+
+		"[key: no-percent] [field: NoPercent] cannot parse as float: strconv.ParseFloat: parsing \"50%\": invalid syntax, "+
+		"[key: na-n] [field: NaN] value NaN is out of range 0..1, "+
+		"[key: inf] [field: Inf] value +Inf is out of range 0.., "+
+		"[key: short-group] [field: ShortGroup] cannot parse as int: invalid digit grouping in \"1,00\", "+
+		"[key: single-digits] [field: SingleDigits] cannot parse as int: invalid digit grouping in \"1,0,0\", "+
+		"[key: long-group] [field: LongGroup] cannot parse as float: invalid digit grouping in \"1234,567.5\"", err)
+
+	assertEqual(t, 1000, dummy.Underscore)
+	assertEqual(t, 1000000, dummy.Thousands)
+	assertEqual(t, 1234.5, dummy.Locale)
+	assertEqual(t, 0.5, dummy.Percent)
+	assertEqual(t, 8080, dummy.Port)
+	assertEqual(t, 0, dummy.Min)
+	assertEqual(t, float32(0.25), dummy.Ratio)
+	assertEqual(t, float32(0.1), dummy.Tenth)
+	assertEqual(t, -1000, dummy.Negative1000)
+}
+
+func TestParseNumbers_CustomRange(t *testing.T) {
+	var dummy struct {
+		Timeout     time.Duration `range:"1s..1m"`
+		Size        ByteSize      `range:"..1KiB"`
+		Level       slog.Level    `range:"info.."`
+		TooLong     time.Duration `range:"1s..1m"`
+		TooLarge    ByteSize      `range:"..1KiB"`
+		TooVerbose  slog.Level    `range:"info.."`
+		InvalidTag  time.Duration `range:"1s..forever"`
+		Unsupported netip.Addr    `range:"1..2"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"timeout":     "30s",
+			"size":        "512B",
+			"level":       "warn",
+			"too-long":    "1h",
+			"too-large":   "1MiB",
+			"too-verbose": "debug",
+			"invalid-tag": "30s",
+			"unsupported": "::1",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: too-long] [field: TooLong] value 1h0m0s is out of range 1s..1m, "+
+		"[key: too-large] [field: TooLarge] value 1048576 is out of range ..1KiB, "+
+		"[key: too-verbose] [field: TooVerbose] value DEBUG is out of range info.., "+
+		"[key: invalid-tag] [field: InvalidTag] invalid range tag \"1s..forever\", "+
+		"[key: unsupported] [field: Unsupported] range is not supported for type netip.Addr", err)
+
+	assertEqual(t, 30*time.Second, dummy.Timeout)
+	assertEqual(t, ByteSize(512), dummy.Size)
+	assertEqual(t, slog.LevelWarn, dummy.Level)
+}
@@ -1,17 +1,27 @@
 package structparse
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"net/url"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
 )
 
 const (
-	structTagParse     = "parse"
-	structTagDelimiter = "delimiter"
+	structTagParse      = "parse"
+	structTagDelimiter  = "delimiter"
+	structTagRequired   = "required"
+	structTagOptional   = "optional"
+	structTagSecret     = "secret"
+	structTagAliases    = "aliases"
+	structTagDeprecated = "deprecated"
+	structTagPrefix     = "prefix"
+	structTagBool       = "bool"
+
+	boolLenient = "lenient"
 )
 
 type Config struct {
@@ -19,16 +29,38 @@ type Config struct {
 	KeyFmt        KeyFmt
 	Transformers  []Transformer
 	IgnoreMissing bool
+
+	// LenientBool enables the lenient boolean vocabulary for all bool fields that do
+	// not set the bool tag, see parseBoolLenient.
+	LenientBool bool
+
+	// InferPolicy determines which types are inferred for interface{} fields. If zero,
+	// InferDefault is used. It can be overridden per field with the infer tag.
+	InferPolicy InferPolicy
+
+	// OnDeprecated is called whenever a value is read from an alias that is marked
+	// as deprecated. See DeprecationLogger for a slog based implementation.
+	OnDeprecated func(field Field, alias string)
+
+	// cache is created per Parse call and shared by all fields
+	cache *parseCache
 }
 
 func Parse(cfg Config, dst interface{}) error {
+	return ParseAt(cfg, nil, dst)
+}
+
+// ParseAt is like Parse but roots dst at the given key prefix, e.g. ParseAt(cfg, []string{"Replica"}, &db)
+// reads REPLICA_HOST instead of HOST when using KeyFmtEnv.
+func ParseAt(cfg Config, prefix []string, dst interface{}) error {
 	if cfg.Src == nil {
 		return errors.New("structparse: source is missing")
 	}
 	if cfg.KeyFmt == nil {
 		return errors.New("structparse: key formatter is missing")
 	}
-	return parse(cfg, dst, nil)
+	cfg.cache = newParseCache()
+	return parse(cfg, dst, append([]string(nil), prefix...), "")
 }
 
 type ParseError []*FieldError
@@ -44,6 +76,21 @@ func (err ParseError) Error() string {
 	return strings.Join(all, ", ")
 }
 
+func (err ParseError) MarshalJSON() ([]byte, error) {
+	if err == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]*FieldError(err))
+}
+
+func (err ParseError) Unwrap() []error {
+	all := make([]error, 0, len(err))
+	for _, err := range err {
+		all = append(all, err)
+	}
+	return all
+}
+
 func (err ParseError) Format(s fmt.State, verb rune) {
 	if len(err) == 0 {
 		return
@@ -62,7 +109,7 @@ func (err ParseError) Format(s fmt.State, verb rune) {
 	}
 }
 
-func parse(cfg Config, dst interface{}, parentKeys []string) error {
+func parse(cfg Config, dst interface{}, parentKeys []string, parentPath string) error {
 	v := reflect.Indirect(reflect.ValueOf(dst))
 	if v.Kind() != reflect.Struct {
 		return errors.New("structparse: dst is not a struct")
@@ -74,10 +121,7 @@ func parse(cfg Config, dst interface{}, parentKeys []string) error {
 		fieldType := v.Type().Field(i)
 		fieldValue := v.Field(i)
 
-		err := parseField(cfg, fieldType, fieldValue, parentKeys)
-		if errors.Is(err, ErrSourceKeyNotFound) && cfg.IgnoreMissing {
-			continue
-		}
+		err := parseField(cfg, fieldType, fieldValue, parentKeys, parentPath)
 		if err != nil {
 			if as := ParseError(nil); errors.As(err, &as) {
 				retErr = append(retErr, as...)
@@ -100,7 +144,12 @@ func parse(cfg Config, dst interface{}, parentKeys []string) error {
 type FieldError struct {
 	Cause     error
 	FieldName string
+	// FieldPath is the full Go field path, e.g. Nested.FieldA.
+	FieldPath string
 	KeyName   string
+	// File is the path the value was read from if the source reads keys from files,
+	// see FileSource.
+	File string
 }
 
 func (err *FieldError) Unwrap() error {
@@ -108,17 +157,128 @@ func (err *FieldError) Unwrap() error {
 }
 
 func (err *FieldError) Error() string {
-	return fmt.Sprintf("[key: %s] [field: %s] %s", err.KeyName, err.FieldName, err.Cause)
+	field := err.FieldPath
+	if len(field) == 0 {
+		field = err.FieldName
+	}
+	if len(err.KeyName) == 0 {
+		return fmt.Sprintf("[field: %s] %s", field, err.Cause)
+	}
+	return fmt.Sprintf("[key: %s] [field: %s] %s", err.KeyName, field, err.Cause)
+}
+
+type fieldErrorJSON struct {
+	Key   string `json:"key"`
+	Field string `json:"field"`
+	Path  string `json:"path"`
+	Code  string `json:"code"`
+	Error string `json:"error"`
+	Value string `json:"value,omitempty"`
+	File  string `json:"file,omitempty"`
 }
 
-func parseField(cfg Config, field reflect.StructField, fieldValue reflect.Value, parentKeys []string) error {
-	name := field.Name
-	if override, exists := field.Tag.Lookup(structTagParse); exists {
-		name = override
+func (err *FieldError) MarshalJSON() ([]byte, error) {
+	v := fieldErrorJSON{
+		Key:   err.KeyName,
+		Field: err.FieldName,
+		Path:  err.FieldPath,
+		Code:  "error",
+		Error: err.Cause.Error(),
+		File:  err.File,
 	}
+	var (
+		missingErr     *MissingKeyError
+		invalidErr     *InvalidValueError
+		unsupportedErr *UnsupportedTypeError
+		validationErr  *ValidationError
+		sourceErr      *SourceError
+	)
+	switch {
+	case errors.As(err.Cause, &missingErr):
+		v.Code = "missing_key"
+	case errors.As(err.Cause, &validationErr):
+		v.Code = "validation"
+	case errors.As(err.Cause, &invalidErr):
+		v.Code = "invalid_value"
+		v.Value = invalidErr.Value
+	case errors.As(err.Cause, &unsupportedErr):
+		v.Code = "unsupported_type"
+	case errors.As(err.Cause, &sourceErr):
+		v.Code = "source"
+	}
+	return json.Marshal(v)
+}
+
+func isOptional(cfg Config, tag reflect.StructTag) bool {
+	if tag.Get(structTagRequired) == "true" {
+		return false
+	}
+	if tag.Get(structTagOptional) == "true" {
+		return true
+	}
+	return cfg.IgnoreMissing
+}
+
+type tagOptions struct {
+	name     string
+	prefix   *string
+	absolute bool
+	inline   bool
+}
+
+// parseTagOptions reads the parse tag in the format name[,option...] and the prefix tag.
+// Supported options are absolute, which drops all parent keys, and inline/squash, which
+// makes the field contribute no key segment.
+func parseTagOptions(field reflect.StructField) tagOptions {
+	opts := tagOptions{name: field.Name}
+	if tag, exists := field.Tag.Lookup(structTagParse); exists {
+		parts := strings.Split(tag, ",")
+		if len(parts[0]) > 0 {
+			opts.name = parts[0]
+		}
+		for _, option := range parts[1:] {
+			switch strings.TrimSpace(option) {
+			case "absolute":
+				opts.absolute = true
+			case "inline", "squash":
+				opts.inline = true
+			}
+		}
+	}
+	if prefix, exists := field.Tag.Lookup(structTagPrefix); exists {
+		opts.prefix = &prefix
+	}
+	return opts
+}
+
+// segments returns the key segments the field adds to its parent keys.
+func (opts tagOptions) segments() []string {
+	if opts.inline {
+		return nil
+	}
+	if opts.prefix != nil {
+		if len(*opts.prefix) == 0 {
+			return nil
+		}
+		return strings.Split(*opts.prefix, ".")
+	}
+	return []string{opts.name}
+}
+
+func joinFieldPath(parentPath, name string) string {
+	if len(parentPath) == 0 {
+		return name
+	}
+	return parentPath + "." + name
+}
+
+func parseField(cfg Config, field reflect.StructField, fieldValue reflect.Value, parentKeys []string, parentPath string) error {
+	path := joinFieldPath(parentPath, field.Name)
+
+	opts := parseTagOptions(field)
 
 	// support skipping fields
-	if name == "-" {
+	if opts.name == "-" {
 		return nil
 	}
 
@@ -129,7 +289,7 @@ func parseField(cfg Config, field reflect.StructField, fieldValue reflect.Value,
 			if !fieldValue.CanAddr() || !fieldValue.Addr().CanInterface() {
 				return nil
 			}
-			return parse(cfg, fieldValue.Addr().Interface(), parentKeys)
+			return parse(cfg, fieldValue.Addr().Interface(), parentKeys, path)
 		case reflect.Ptr:
 			if fieldValue.IsNil() {
 				if !fieldValue.CanSet() {
@@ -137,11 +297,12 @@ func parseField(cfg Config, field reflect.StructField, fieldValue reflect.Value,
 				}
 				fieldValue.Set(reflect.New(field.Type.Elem()))
 			}
-			return parse(cfg, fieldValue.Elem().Addr().Interface(), parentKeys)
+			return parse(cfg, fieldValue.Elem().Addr().Interface(), parentKeys, path)
 		default:
 			return &FieldError{
-				Cause:     fmt.Errorf("unsupported anonymus type %s", field.Type.Kind()),
-				FieldName: field.Type.Name(),
+				Cause:     &UnsupportedTypeError{Type: field.Type, Embedded: true},
+				FieldName: field.Name,
+				FieldPath: path,
 			}
 		}
 	}
@@ -150,13 +311,17 @@ func parseField(cfg Config, field reflect.StructField, fieldValue reflect.Value,
 		return nil
 	}
 
-	parentKeys = append(parentKeys, name)
+	fieldParentKeys := parentKeys
+	if opts.absolute {
+		parentKeys = nil
+	}
+	parentKeys = append(parentKeys[:len(parentKeys):len(parentKeys)], opts.segments()...)
 
 	// handle nested structs that do not have a custom parser
 	switch field.Type.Kind() {
 	case reflect.Struct:
 		if _, ok := getCustomParser(field.Type); !ok {
-			return parse(cfg, fieldValue.Addr().Interface(), parentKeys)
+			return parse(cfg, fieldValue.Addr().Interface(), parentKeys, path)
 		}
 	case reflect.Ptr:
 		if fieldValue.Type().Elem().Kind() == reflect.Struct {
@@ -164,42 +329,176 @@ func parseField(cfg Config, field reflect.StructField, fieldValue reflect.Value,
 				if fieldValue.IsNil() {
 					fieldValue.Set(reflect.New(field.Type.Elem()))
 				}
-				return parse(cfg, fieldValue.Elem().Addr().Interface(), parentKeys)
+				return parse(cfg, fieldValue.Elem().Addr().Interface(), parentKeys, path)
 			}
 		}
+	case reflect.Map:
+		if isStructMap(field.Type) {
+			return parseStructMap(cfg, field, fieldValue, parentKeys, path)
+		}
+	case reflect.Interface:
+		if hasImplementations(field.Type) {
+			return parseImplementation(cfg, field, fieldValue, parentKeys, path)
+		}
+	}
+
+	key := cfg.KeyFmt.Format(append([]string(nil), parentKeys...))
+
+	// key options only make sense on fields that contribute keys to their children
+	if opts.inline || opts.prefix != nil {
+		cause := errors.New("prefix tag is only supported on nested struct fields")
+		if opts.inline {
+			cause = errors.New("parse tag option inline is only supported on nested struct fields")
+		}
+		return &FieldError{Cause: cause, FieldName: field.Name, FieldPath: path, KeyName: key}
+	}
+	desc := Field{
+		Key:         key,
+		StructField: field,
+		Path:        path,
+		ParentKeys:  append([]string(nil), fieldParentKeys...),
+		Type:        field.Type,
+		cache:       cfg.cache,
+	}
+
+	value, ok, err := readValue(cfg, desc)
+	if !ok {
+		return err
+	}
+
+	tag := field.Tag
+	if _, ok := tag.Lookup(structTagBool); !ok && cfg.LenientBool {
+		tag = withTag(tag, structTagBool, boolLenient)
+	}
+	if _, ok := tag.Lookup(structTagInfer); !ok && cfg.InferPolicy != 0 {
+		tag = withTag(tag, structTagInfer, cfg.InferPolicy.String())
+	}
+
+	err = assignValue(fieldValue, value, tag)
+	if err == nil {
+		err = validateCollection(fieldValue, field.Tag)
+	}
+	if as := (*ValidationError)(nil); errors.As(err, &as) {
+		as.Key, as.FieldPath = key, path
+		if field.Tag.Get(structTagSecret) == "true" {
+			as.Msg = redactString(as.Msg, value)
+		}
+		return &FieldError{Cause: err, FieldName: field.Name, FieldPath: path, KeyName: key, File: sourceFile(cfg, key)}
+	}
+	if err != nil {
+		if as := (*UnsupportedTypeError)(nil); !errors.As(err, &as) {
+			err = newInvalidValueError(err, key, path, field, value)
+		}
+		return &FieldError{Cause: err, FieldName: field.Name, FieldPath: path, KeyName: key, File: sourceFile(cfg, key)}
+	}
+
+	return nil
+}
+
+// readValue looks up the field's value and passes it through all transformers. ok is false
+// if the field is to be left untouched, either because it is skipped, optional and missing
+// or because err is set.
+func readValue(cfg Config, field Field) (value string, ok bool, err error) {
+	value, err = lookup(cfg, field)
+	if err != nil && !errors.Is(err, ErrSourceKeyNotFound) {
+		err = &SourceError{Key: field.Key, Cause: err}
 	}
 
-	key := cfg.KeyFmt.Format(parentKeys)
-	value, err := cfg.Src.Get(key)
 	for _, t := range cfg.Transformers {
-		value, err = t.Transform(key, value, err, field.Tag)
+		value, err = transform(t, field, value, err)
 	}
 	if errors.Is(err, ErrTransformerSkipKey) {
-		return nil
+		return "", false, nil
+	}
+	name := field.StructField.Name
+	if errors.Is(err, ErrSourceKeyNotFound) {
+		if isOptional(cfg, field.StructField.Tag) {
+			return "", false, nil
+		}
+		return "", false, &FieldError{Cause: &MissingKeyError{Key: field.Key, FieldPath: field.Path}, FieldName: name, FieldPath: field.Path, KeyName: field.Key}
 	}
 	if err != nil {
-		return &FieldError{Cause: err, FieldName: field.Name, KeyName: key}
+		return "", false, &FieldError{Cause: err, FieldName: name, FieldPath: field.Path, KeyName: field.Key, File: sourceFile(cfg, field.Key)}
 	}
+	return value, true, nil
+}
 
-	err = assignValue(fieldValue, value, field.Tag)
-	if err != nil {
-		return &FieldError{Cause: err, FieldName: field.Name, KeyName: key}
+// sourceFile returns the file the value of key was read from, if any.
+func sourceFile(cfg Config, key string) string {
+	if src, ok := cfg.Src.(interface{ File(string) (string, bool) }); ok {
+		path, _ := src.File(key)
+		return path
 	}
+	return ""
+}
 
-	return nil
+// lookup reads the value of the field's key and falls back to its aliases in order.
+func lookup(cfg Config, field Field) (string, error) {
+	value, err := cfg.Src.Get(field.Key)
+	if !errors.Is(err, ErrSourceKeyNotFound) {
+		return value, err
+	}
+
+	aliases, ok := field.StructField.Tag.Lookup(structTagAliases)
+	if !ok || len(aliases) == 0 {
+		return value, err
+	}
+	for _, alias := range strings.Split(aliases, ",") {
+		alias = strings.TrimSpace(alias)
+		value, err := cfg.Src.Get(alias)
+		if errors.Is(err, ErrSourceKeyNotFound) {
+			continue
+		}
+		if err == nil && cfg.OnDeprecated != nil && isDeprecated(field.StructField.Tag, alias) {
+			cfg.OnDeprecated(field, alias)
+		}
+		return value, err
+	}
+	return "", ErrSourceKeyNotFound
 }
 
-type AssignError struct {
-	Cause error
-	Msg   string
+// isDeprecated reports whether alias is listed in the deprecated tag. deprecated:"true"
+// marks all aliases as deprecated.
+func isDeprecated(tag reflect.StructTag, alias string) bool {
+	deprecated := tag.Get(structTagDeprecated)
+	if deprecated == "true" {
+		return true
+	}
+	for _, d := range strings.Split(deprecated, ",") {
+		if strings.TrimSpace(d) == alias {
+			return true
+		}
+	}
+	return false
 }
 
-func (err *AssignError) Unwrap() error {
-	return err.Cause
+// parseBoolLenient accepts yes/no, y/n, on/off and enabled/disabled in addition to the
+// values accepted by strconv.ParseBool, case-insensitively. An empty value is true, so
+// that flag-like keys only have to be present.
+func parseBoolLenient(src string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(src)) {
+	case "", "1", "t", "true", "y", "yes", "on", "enable", "enabled":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off", "disable", "disabled":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", src)
 }
 
-func (err *AssignError) Error() string {
-	return fmt.Sprintf("%s: %s", err.Msg, err.Cause)
+// convertCustomValue converts values returned by custom parsers between T and *T,
+// as parsers are registered for both.
+func convertCustomValue(v reflect.Value, typ reflect.Type) reflect.Value {
+	switch {
+	case v.Type() == typ:
+		return v
+	case v.Kind() == reflect.Ptr && v.Type().Elem() == typ:
+		return v.Elem()
+	case typ.Kind() == reflect.Ptr && typ.Elem() == v.Type():
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr
+	}
+	return v
 }
 
 func assignValue(dst reflect.Value, src string, tag reflect.StructTag) error {
@@ -208,68 +507,126 @@ func assignValue(dst reflect.Value, src string, tag reflect.StructTag) error {
 	if p, ok := getCustomParser(typ); ok {
 		val, err := p(src, tag)
 		if err != nil {
+			named := typ
+			if named.Kind() == reflect.Ptr {
+				named = named.Elem()
+			}
 			return &AssignError{
 				Cause: err,
-				Msg:   fmt.Sprintf("cannot parse as custom type (%s %s)", typ.PkgPath(), typ.Name()),
+				Msg:   fmt.Sprintf("cannot parse as custom type (%s %s)", named.PkgPath(), named.Name()),
 			}
 		}
-		dst.Set(reflect.ValueOf(val))
+		v := convertCustomValue(reflect.ValueOf(val), typ)
+		if err := checkCustomRange(v, p, tag); err != nil {
+			return err
+		}
+		dst.Set(v)
+		return nil
+	}
+
+	if e, ok := getEnum(typ); ok && typ.Kind() != reflect.Ptr {
+		val, err := e.parse(src)
+		if err != nil {
+			return &AssignError{
+				Cause: err,
+				Msg:   fmt.Sprintf("cannot parse as enum (%s %s)", typ.PkgPath(), typ.Name()),
+			}
+		}
+		dst.Set(val)
 		return nil
 	}
 
 	switch typ.Kind() {
 	case reflect.Bool:
-		v, err := strconv.ParseBool(src)
+		parseBool := strconv.ParseBool
+		if tag.Get(structTagBool) == boolLenient {
+			parseBool = parseBoolLenient
+		}
+		v, err := parseBool(src)
 		if err != nil {
 			return &AssignError{Cause: err, Msg: "cannot parse as bool"}
 		}
 		dst.SetBool(v)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := strconv.ParseInt(src, 0, typ.Bits())
-		if err != nil {
-			return &AssignError{Cause: err, Msg: "cannot parse as int"}
+		var v int64
+		if unit, ok := tag.Lookup(structTagUnit); ok {
+			q, err := parseQuantity(src, unit, typ.Bits(), true)
+			if err != nil {
+				return &AssignError{Cause: err, Msg: "cannot parse as int"}
+			}
+			v = q.Int64()
+		} else {
+			normalized, err := normalizeNumber(src, tag, false)
+			if err != nil {
+				return &AssignError{Cause: err, Msg: "cannot parse as int"}
+			}
+			v, err = strconv.ParseInt(normalized, 0, typ.Bits())
+			if err != nil {
+				return &AssignError{Cause: err, Msg: "cannot parse as int"}
+			}
+		}
+		if err := checkRange(new(big.Rat).SetInt64(v), strconv.FormatInt(v, 10), tag); err != nil {
+			return err
 		}
 		dst.SetInt(v)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		v, err := strconv.ParseUint(src, 0, typ.Bits())
-		if err != nil {
-			return &AssignError{Cause: err, Msg: "cannot parse as uint"}
+		var v uint64
+		if unit, ok := tag.Lookup(structTagUnit); ok {
+			q, err := parseQuantity(src, unit, typ.Bits(), false)
+			if err != nil {
+				return &AssignError{Cause: err, Msg: "cannot parse as uint"}
+			}
+			v = q.Uint64()
+		} else {
+			normalized, err := normalizeNumber(src, tag, false)
+			if err != nil {
+				return &AssignError{Cause: err, Msg: "cannot parse as uint"}
+			}
+			v, err = strconv.ParseUint(normalized, 0, typ.Bits())
+			if err != nil {
+				return &AssignError{Cause: err, Msg: "cannot parse as uint"}
+			}
+		}
+		if err := checkRange(new(big.Rat).SetInt(new(big.Int).SetUint64(v)), strconv.FormatUint(v, 10), tag); err != nil {
+			return err
 		}
 		dst.SetUint(v)
 
 	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(src, typ.Bits())
+		normalized, err := normalizeNumber(src, tag, true)
+		if err != nil {
+			return &AssignError{Cause: err, Msg: "cannot parse as float"}
+		}
+		f, err := parseFloat(normalized, typ.Bits(), tag)
 		if err != nil {
 			return &AssignError{Cause: err, Msg: "cannot parse as float"}
 		}
+		if err := checkFloatRange(f, typ.Bits(), tag); err != nil {
+			return err
+		}
 		dst.SetFloat(f)
 
-	case reflect.Map:
-		values, err := url.ParseQuery(src)
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(src, typ.Bits())
 		if err != nil {
-			return &AssignError{Cause: err, Msg: "cannot parse as map"}
+			return &AssignError{Cause: err, Msg: "cannot parse as complex"}
 		}
+		dst.SetComplex(c)
 
-		dst.Set(reflect.MakeMap(typ))
-
-		for k, v := range values {
-			if len(v) == 0 {
-				continue
-			}
-			keyVal := reflect.New(typ.Key())
-			err := assignValue(keyVal, k, tag)
-			if err != nil {
-				return err
-			}
-			elemVal := reflect.New(typ.Elem())
-			err = assignValue(elemVal, v[0], tag)
-			if err != nil {
-				return err
-			}
-			dst.SetMapIndex(keyVal.Elem(), elemVal.Elem())
+	case reflect.Interface:
+		if typ.NumMethod() > 0 {
+			return &UnsupportedTypeError{Type: typ}
+		}
+		v, err := inferValue(src, tag)
+		if err != nil {
+			return &AssignError{Cause: err, Msg: "cannot infer value"}
 		}
+		dst.Set(reflect.ValueOf(v))
+
+	case reflect.Map:
+		return assignMap(dst, src, tag)
 
 	case reflect.Ptr:
 		if dst.IsNil() {
@@ -278,33 +635,16 @@ func assignValue(dst reflect.Value, src string, tag reflect.StructTag) error {
 		return assignValue(dst.Elem(), src, tag)
 
 	case reflect.Slice:
-		if len(src) == 0 {
-			return nil
-		}
-		if typ.Elem().Kind() == reflect.Uint8 {
-			// handle []byte slices as string
-			dst.SetBytes([]byte(src))
-		} else {
-			delimiter := tag.Get(structTagDelimiter)
-			if len(delimiter) == 0 {
-				delimiter = ","
-			}
-			parts := strings.Split(src, delimiter)
-			sl := reflect.MakeSlice(typ, len(parts), len(parts))
-			for i, p := range parts {
-				err := assignValue(sl.Index(i), p, tag)
-				if err != nil {
-					return err
-				}
-			}
-			dst.Set(sl)
-		}
+		return assignSlice(dst, src, tag)
+
+	case reflect.Array:
+		return assignArray(dst, src, tag)
 
 	case reflect.String:
 		dst.SetString(src)
 
 	default:
-		return fmt.Errorf("unsupported dst type %s", typ.Kind())
+		return &UnsupportedTypeError{Type: typ}
 	}
 
 	return nil
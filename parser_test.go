@@ -1,7 +1,11 @@
 package structparse
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"testing"
 )
 
@@ -25,13 +29,59 @@ func TestParse_Errors(t *testing.T) {
 	}
 	err := Parse(cfg, &dummy)
 
-	expected := "[key: nested-field-a] [field: FieldA] cannot parse as bool: strconv.ParseBool: parsing \"invalid\": invalid syntax\n" +
-		"[key: nested-field-b] [field: FieldB] cannot parse as int: strconv.ParseInt: parsing \"invalid\": invalid syntax\n" +
+	expected := "[key: nested-field-a] [field: Nested.FieldA] cannot parse as bool: strconv.ParseBool: parsing \"invalid\": invalid syntax\n" +
+		"[key: nested-field-b] [field: Nested.FieldB] cannot parse as int: strconv.ParseInt: parsing \"invalid\": invalid syntax\n" +
 		"[key: field-c] [field: FieldC] cannot parse as bool: strconv.ParseBool: parsing \"invalid\": invalid syntax\n" +
 		"[key: missing] [field: Missing] key not found"
 	assertEqual(t, expected, fmt.Sprintf("%+v", err))
 }
 
+func TestParse_FieldPath(t *testing.T) {
+	type Embedded struct {
+		Field int
+	}
+	var dummy struct {
+		Embedded
+		Nested struct {
+			Field int
+		}
+		Other struct {
+			Field int
+		}
+		Invalid *int
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"field":        "invalid",
+			"nested-field": "invalid",
+			"other-field":  "1",
+			"invalid":      "invalid",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: field] [field: Embedded.Field] cannot parse as int: strconv.ParseInt: parsing \"invalid\": invalid syntax, "+
+		"[key: nested-field] [field: Nested.Field] cannot parse as int: strconv.ParseInt: parsing \"invalid\": invalid syntax, "+
+		"[key: invalid] [field: Invalid] cannot parse as int: strconv.ParseInt: parsing \"invalid\": invalid syntax", err)
+
+	var parseErr ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected parse error but got '%s'", err)
+	}
+	assertEqual(t, "Field", parseErr[1].FieldName)
+	assertEqual(t, "Nested.Field", parseErr[1].FieldPath)
+
+	data, err := json.Marshal(parseErr[1:2])
+	assertEqual(t, nil, err)
+	assertEqual(t, `[{"key":"nested-field","field":"Field","path":"Nested.Field","code":"invalid_value",`+
+		`"error":"cannot parse as int: strconv.ParseInt: parsing \"invalid\": invalid syntax","value":"invalid"}]`, string(data))
+
+	data, err = json.Marshal(ParseError(nil))
+	assertEqual(t, nil, err)
+	assertEqual(t, "[]", string(data))
+}
+
 func TestParse_Missing(t *testing.T) {
 	var dummy struct {
 		Missing string
@@ -50,6 +100,32 @@ func TestParse_Missing(t *testing.T) {
 	assertEqual(t, nil, err)
 }
 
+func TestParse_RequiredOptional(t *testing.T) {
+	var dummy struct {
+		Required string `required:"true"`
+		Optional string `optional:"true"`
+		Missing  string
+	}
+
+	cfg := Config{
+		Src:           SourceMap{},
+		KeyFmt:        KeyFmtKebab(),
+		IgnoreMissing: true,
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: required] [field: Required] key not found", err)
+
+	var missingErr *MissingKeyError
+	if assertEqual(t, true, errors.As(err, &missingErr)) {
+		assertEqual(t, "required", missingErr.Key)
+	}
+	assertEqual(t, true, errors.Is(err, ErrSourceKeyNotFound))
+
+	cfg.IgnoreMissing = false
+	err = Parse(cfg, &dummy)
+	assertEqual(t, "[key: required] [field: Required] key not found, [key: missing] [field: Missing] key not found", err)
+}
+
 func TestParse_Types(t *testing.T) {
 	type Embedded1 struct {
 		EmbeddedVal1 string
@@ -207,3 +283,135 @@ func TestParse_Types(t *testing.T) {
 
 	assertEqual(t, "test", dummy.String)
 }
+
+func TestParse_Aliases(t *testing.T) {
+	var dummy struct {
+		DatabaseUrl string `aliases:"DB_URL,DATABASE_URI" deprecated:"DB_URL"`
+		Primary     string `aliases:"OLD_PRIMARY"`
+		Fallback    string `aliases:"FIRST,SECOND" deprecated:"true"`
+		Missing     string `aliases:"ALSO_MISSING"`
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	var deprecated []string
+	log := DeprecationLogger(logger)
+	cfg := Config{
+		Src: SourceMap{
+			"DB_URL":      "old",
+			"PRIMARY":     "primary",
+			"OLD_PRIMARY": "ignored",
+			"SECOND":      "second",
+		},
+		KeyFmt: KeyFmtEnv(),
+		OnDeprecated: func(field Field, alias string) {
+			deprecated = append(deprecated, field.Path+":"+alias)
+			log(field, alias)
+		},
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: MISSING] [field: Missing] key not found", err)
+
+	assertEqual(t, "old", dummy.DatabaseUrl)
+	assertEqual(t, "primary", dummy.Primary)
+	assertEqual(t, "second", dummy.Fallback)
+	assertEqual(t, []string{"DatabaseUrl:DB_URL", "Fallback:SECOND"}, deprecated)
+	assertEqual(t, "level=WARN msg=\"structparse: deprecated key used\" alias=DB_URL key=DATABASE_URL field=DatabaseUrl\n"+
+		"level=WARN msg=\"structparse: deprecated key used\" alias=SECOND key=FALLBACK field=Fallback\n", buf.String())
+}
+
+func TestParse_KeyOptions(t *testing.T) {
+	type Database struct {
+		Host string
+		Url  string `parse:"DatabaseUrl,absolute"`
+	}
+	var dummy struct {
+		App struct {
+			Primary   Database  `prefix:"Db"`
+			Replica   *Database `prefix:"Db.Replica"`
+			Inline    Database  `parse:",inline"`
+			Squash    Database  `parse:",squash"`
+			NoSegment Database  `prefix:""`
+			Renamed   string    `parse:"Name"`
+		}
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"APP_DB_HOST":         "primary",
+			"APP_DB_REPLICA_HOST": "replica",
+			"APP_HOST":            "inline",
+			"APP_NAME":            "app",
+			"DATABASE_URL":        "postgres://",
+		},
+		KeyFmt: KeyFmtEnv(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, nil, err)
+
+	assertEqual(t, "primary", dummy.App.Primary.Host)
+	assertEqual(t, "replica", dummy.App.Replica.Host)
+	assertEqual(t, "inline", dummy.App.Inline.Host)
+	assertEqual(t, "inline", dummy.App.Squash.Host)
+	assertEqual(t, "inline", dummy.App.NoSegment.Host)
+	assertEqual(t, "app", dummy.App.Renamed)
+	assertEqual(t, "postgres://", dummy.App.Primary.Url)
+	assertEqual(t, "postgres://", dummy.App.Replica.Url)
+
+	var invalid struct {
+		Inline string `parse:",inline"`
+		Prefix int    `prefix:"Value"`
+	}
+	err = Parse(cfg, &invalid)
+	assertEqual(t, "[field: Inline] parse tag option inline is only supported on nested struct fields, "+
+		"[key: VALUE] [field: Prefix] prefix tag is only supported on nested struct fields", err)
+}
+
+func TestParse_LenientBool(t *testing.T) {
+	var dummy struct {
+		Yes      bool `bool:"lenient"`
+		Off      bool `bool:"lenient"`
+		Enabled  bool `bool:"lenient"`
+		Flag     bool `bool:"lenient"`
+		Strict   bool
+		Invalid  bool   `bool:"lenient"`
+		Disabled []bool `bool:"lenient"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"yes":      "YES",
+			"off":      "off",
+			"enabled":  "Enabled",
+			"flag":     "",
+			"strict":   "yes",
+			"invalid":  "maybe",
+			"disabled": "n,y",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: strict] [field: Strict] cannot parse as bool: strconv.ParseBool: parsing \"yes\": invalid syntax, "+
+		"[key: invalid] [field: Invalid] cannot parse as bool: invalid boolean \"maybe\"", err)
+
+	assertEqual(t, true, dummy.Yes)
+	assertEqual(t, false, dummy.Off)
+	assertEqual(t, true, dummy.Enabled)
+	assertEqual(t, true, dummy.Flag)
+	assertEqual(t, []bool{false, true}, dummy.Disabled)
+
+	// enable for all fields via config
+	cfg.LenientBool = true
+	dummy.Strict = false
+	err = Parse(cfg, &dummy)
+	assertEqual(t, "[key: invalid] [field: Invalid] cannot parse as bool: invalid boolean \"maybe\"", err)
+	assertEqual(t, true, dummy.Strict)
+}
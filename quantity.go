@@ -0,0 +1,112 @@
+package structparse
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+const (
+	structTagUnit = "unit"
+
+	unitBytes = "bytes"
+	unitSI    = "si"
+)
+
+// ByteSize is a number of bytes that can be parsed from human-friendly values like
+// 512KiB, 1.5GB or 10M. See parseQuantity for the supported suffixes.
+type ByteSize uint64
+
+var (
+	byteSizeSuffixes = map[string]int64{
+		"":    1,
+		"b":   1,
+		"k":   1e3,
+		"kb":  1e3,
+		"m":   1e6,
+		"mb":  1e6,
+		"g":   1e9,
+		"gb":  1e9,
+		"t":   1e12,
+		"tb":  1e12,
+		"p":   1e15,
+		"pb":  1e15,
+		"e":   1e18,
+		"eb":  1e18,
+		"ki":  1 << 10,
+		"kib": 1 << 10,
+		"mi":  1 << 20,
+		"mib": 1 << 20,
+		"gi":  1 << 30,
+		"gib": 1 << 30,
+		"ti":  1 << 40,
+		"tib": 1 << 40,
+		"pi":  1 << 50,
+		"pib": 1 << 50,
+		"ei":  1 << 60,
+		"eib": 1 << 60,
+	}
+	siSuffixes = map[string]int64{
+		"":  1,
+		"k": 1e3,
+		"m": 1e6,
+		"g": 1e9,
+		"t": 1e12,
+		"p": 1e15,
+		"e": 1e18,
+	}
+
+	errQuantityOutOfRange = errors.New("value out of range")
+)
+
+// parseQuantity parses a decimal number followed by an optional suffix. For unitBytes,
+// decimal (k, KB, M, MB, ...) and binary (Ki, KiB, Mi, MiB, ...) suffixes are supported,
+// for unitSI only decimal suffixes without the byte unit. Suffixes are case-insensitive.
+// The result has to be a whole number that fits into an integer with the given bits.
+func parseQuantity(src, unit string, bits int, signed bool) (*big.Int, error) {
+	var suffixes map[string]int64
+	switch unit {
+	case unitBytes:
+		suffixes = byteSizeSuffixes
+	case unitSI:
+		suffixes = siSuffixes
+	default:
+		return nil, fmt.Errorf("unsupported unit %s", unit)
+	}
+
+	src = strings.TrimSpace(src)
+	idx := strings.IndexFunc(src, unicode.IsLetter)
+	if idx < 0 {
+		idx = len(src)
+	}
+	number, suffix := strings.TrimSpace(src[:idx]), strings.ToLower(src[idx:])
+
+	multiplier, ok := suffixes[suffix]
+	if !ok {
+		return nil, fmt.Errorf("unknown suffix %q", src[idx:])
+	}
+	value, ok := new(big.Rat).SetString(number)
+	if !ok || strings.ContainsAny(number, "/eE") {
+		return nil, fmt.Errorf("invalid number %q", number)
+	}
+	value.Mul(value, new(big.Rat).SetInt64(multiplier))
+	if !value.IsInt() {
+		return nil, fmt.Errorf("%q is not a whole number", src)
+	}
+
+	result := value.Num()
+	var min, max *big.Int
+	if signed {
+		max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)), big.NewInt(1))
+		min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(bits-1)))
+	} else {
+		max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1))
+		min = big.NewInt(0)
+	}
+	if result.Cmp(min) < 0 || result.Cmp(max) > 0 {
+		return nil, fmt.Errorf("%w: %s", errQuantityOutOfRange, src)
+	}
+	return result, nil
+}
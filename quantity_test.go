@@ -0,0 +1,51 @@
+package structparse
+
+import (
+	"testing"
+)
+
+func TestParseQuantity(t *testing.T) {
+	var dummy struct {
+		Size      ByteSize
+		Fraction  ByteSize
+		Decimal   ByteSize
+		Short     ByteSize
+		Plain     ByteSize
+		Memory    int64  `unit:"bytes"`
+		Count     uint32 `unit:"si"`
+		Negative  int    `unit:"si"`
+		Overflow  uint8  `unit:"bytes"`
+		Fractions int    `unit:"si"`
+		Unknown   int    `unit:"si"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"size":      "512KiB",
+			"fraction":  "1.5GB",
+			"decimal":   "1.5 GiB",
+			"short":     "10M",
+			"plain":     "100",
+			"memory":    "2Gi",
+			"count":     "2k",
+			"negative":  "-3k",
+			"overflow":  "1KiB",
+			"fractions": "1.0001k",
+			"unknown":   "2KB",
+		},
+		KeyFmt: KeyFmtKebab(),
+	}
+	err := Parse(cfg, &dummy)
+	assertEqual(t, "[key: overflow] [field: Overflow] cannot parse as uint: value out of range: 1KiB, "+
+		"[key: fractions] [field: Fractions] cannot parse as int: \"1.0001k\" is not a whole number, "+
+		"[key: unknown] [field: Unknown] cannot parse as int: unknown suffix \"KB\"", err)
+
+	assertEqual(t, 512*1024, dummy.Size)
+	assertEqual(t, 1500000000, dummy.Fraction)
+	assertEqual(t, 1610612736, dummy.Decimal)
+	assertEqual(t, 10000000, dummy.Short)
+	assertEqual(t, 100, dummy.Plain)
+	assertEqual(t, 2<<30, dummy.Memory)
+	assertEqual(t, 2000, dummy.Count)
+	assertEqual(t, -3000, dummy.Negative)
+}
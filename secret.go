@@ -0,0 +1,171 @@
+package structparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+const structTagSecretRef = "secretref"
+
+type SecretResolver interface {
+	Resolve(ref *url.URL) (string, error)
+}
+
+type SecretResolverFunc func(ref *url.URL) (string, error)
+
+func (f SecretResolverFunc) Resolve(ref *url.URL) (string, error) {
+	return f(ref)
+}
+
+// SecretResolvers maps URI schemes to the resolver responsible for them.
+type SecretResolvers struct {
+	mu        sync.Mutex
+	resolvers map[string]SecretResolver
+}
+
+// NewSecretResolvers returns a registry with the file:// and env:// resolvers registered.
+func NewSecretResolvers() *SecretResolvers {
+	r := &SecretResolvers{resolvers: make(map[string]SecretResolver)}
+	r.Register("file", SecretResolverFile())
+	r.Register("env", SecretResolverEnv())
+	return r
+}
+
+func (r *SecretResolvers) Register(scheme string, resolver SecretResolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scheme = strings.ToLower(scheme)
+	if _, ok := r.resolvers[scheme]; ok {
+		panic(fmt.Sprintf("secret resolver already registered for: %s", scheme))
+	}
+	r.resolvers[scheme] = resolver
+}
+
+func (r *SecretResolvers) get(scheme string) (SecretResolver, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	resolver, ok := r.resolvers[strings.ToLower(scheme)]
+	return resolver, ok
+}
+
+// TransformerSecret replaces values of fields tagged with secretref:"true" that are URIs
+// with a registered scheme by the secret the matching resolver returns. Lookups are cached
+// within one Parse call. Resolved values are not redacted from errors unless the field is
+// also tagged with secret:"true".
+func TransformerSecret(resolvers *SecretResolvers) Transformer {
+	return FieldTransformFunc(func(field Field, srcValue string, srcErr error) (string, error) {
+		if field.StructField.Tag.Get(structTagSecretRef) != "true" {
+			return srcValue, srcErr
+		}
+		if srcErr != nil || !strings.Contains(srcValue, ":") {
+			return srcValue, srcErr
+		}
+		ref, err := url.Parse(srcValue)
+		if err != nil || len(ref.Scheme) == 0 {
+			return srcValue, nil
+		}
+		resolver, ok := resolvers.get(ref.Scheme)
+		if !ok {
+			return srcValue, nil
+		}
+
+		return field.cache.get("secret:"+srcValue, func() (string, error) {
+			value, err := resolver.Resolve(ref)
+			if err != nil {
+				return "", fmt.Errorf("cannot resolve secret %s://: %w", ref.Scheme, err)
+			}
+			return value, nil
+		})
+	})
+}
+
+// SecretResolverFile resolves file:///path/to/secret by reading the file.
+func SecretResolverFile() SecretResolver {
+	return SecretResolverFunc(func(ref *url.URL) (string, error) {
+		if len(ref.Host) != 0 {
+			return "", fmt.Errorf("unexpected host %s, use file:///path", ref.Host)
+		}
+		content, err := os.ReadFile(ref.Path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(content), "\n"), nil
+	})
+}
+
+// SecretResolverEnv resolves env://NAME by reading the environment variable.
+func SecretResolverEnv() SecretResolver {
+	return SecretResolverFunc(func(ref *url.URL) (string, error) {
+		name := ref.Host
+		if len(name) == 0 {
+			name = ref.Opaque
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	})
+}
+
+// SecretResolverHTTP resolves references by requesting them via GET. It is meant to be
+// registered for a dedicated scheme such as vault+https, whose prefix up to the "+" is
+// dropped for the request. If the reference has a fragment, the response is decoded as a
+// JSON object and the fragment selects its field. If client is nil, http.DefaultClient is used.
+func SecretResolverHTTP(client *http.Client, header http.Header) SecretResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return SecretResolverFunc(func(ref *url.URL) (string, error) {
+		u := *ref
+		u.Fragment = ""
+		if i := strings.LastIndex(u.Scheme, "+"); i != -1 {
+			u.Scheme = u.Scheme[i+1:]
+		}
+
+		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		if len(ref.Fragment) == 0 {
+			return string(body), nil
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return "", err
+		}
+		value, ok := fields[ref.Fragment]
+		if !ok {
+			return "", fmt.Errorf("field %s not found", ref.Fragment)
+		}
+		if str, ok := value.(string); ok {
+			return str, nil
+		}
+		return fmt.Sprint(value), nil
+	})
+}
@@ -0,0 +1,85 @@
+package structparse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransformerSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(path, []byte("from-file\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	t.Setenv("STRUCTPARSE_SECRET", "from-env")
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("X-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"password":"from-vault"}`))
+	}))
+	defer server.Close()
+
+	resolvers := NewSecretResolvers()
+	resolvers.Register("vault+http", SecretResolverHTTP(server.Client(), http.Header{"X-Token": []string{"token"}}))
+	vaultRef := "vault+" + server.URL + "/v1/kv/app#password"
+
+	var dummy struct {
+		File       string `secretref:"true"`
+		Env        string `secretref:"true"`
+		Vault      string `secretref:"true"`
+		VaultAgain string `secretref:"true"`
+		Plain      string `secretref:"true"`
+		Url        string `secretref:"true"`
+		NotSecret  string
+		RedactOnly string `secret:"true"`
+		FileHost   string `secretref:"true"`
+		Missing    string `secretref:"true"`
+	}
+
+	cfg := Config{
+		Src: SourceMap{
+			"file":        "file://" + path,
+			"env":         "env://STRUCTPARSE_SECRET",
+			"vault":       vaultRef,
+			"vault-again": vaultRef,
+			"plain":       "value",
+			"url":         "postgres://localhost/db",
+			"not-secret":  "env://STRUCTPARSE_SECRET",
+			"redact-only": "env://STRUCTPARSE_SECRET",
+			"file-host":   "file://etc/secret",
+			"missing":     "env://STRUCTPARSE_MISSING",
+		},
+		KeyFmt:       KeyFmtKebab(),
+		Transformers: []Transformer{TransformerSecret(resolvers)},
+	}
+	err = Parse(cfg, &dummy)
+	assertEqual(t, "[key: file-host] [field: FileHost] cannot resolve secret file://: unexpected host etc, use file:///path, "+
+		"[key: missing] [field: Missing] cannot resolve secret env://: environment variable STRUCTPARSE_MISSING is not set", err)
+
+	assertEqual(t, "from-file", dummy.File)
+	assertEqual(t, "from-env", dummy.Env)
+	assertEqual(t, "from-vault", dummy.Vault)
+	assertEqual(t, "from-vault", dummy.VaultAgain)
+	assertEqual(t, "value", dummy.Plain)
+	assertEqual(t, "postgres://localhost/db", dummy.Url)
+	assertEqual(t, "env://STRUCTPARSE_SECRET", dummy.NotSecret)
+	assertEqual(t, "env://STRUCTPARSE_SECRET", dummy.RedactOnly)
+	assertEqual(t, 1, requests)
+
+	// already registered
+	func() {
+		defer func() {
+			p := recover()
+			assertEqual(t, "secret resolver already registered for: file", p)
+		}()
+		resolvers.Register("file", nil)
+	}()
+}
@@ -2,31 +2,60 @@ package structparse
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
 	"os"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultFileSuffix = "_FILE"
 )
 
 var (
 	ErrSourceKeyNotFound = errors.New("key not found")
+	ErrSourceKeyConflict = errors.New("key conflict")
 )
 
 type Source interface {
 	Get(key string) (string, error)
 }
 
+// KeyLister is implemented by sources that can enumerate their keys. It is required
+// to discover the entries of map fields with struct values.
+type KeyLister interface {
+	Keys() []string
+}
+
 type SourceFunc func(key string) (string, error)
 
 func (src SourceFunc) Get(key string) (string, error) {
 	return src(key)
 }
 
-func SourceEnv() Source {
-	return SourceFunc(func(key string) (string, error) {
-		if val, exists := os.LookupEnv(key); exists {
-			return val, nil
+type envSource struct{}
+
+func (envSource) Get(key string) (string, error) {
+	if val, exists := os.LookupEnv(key); exists {
+		return val, nil
+	}
+	return "", ErrSourceKeyNotFound
+}
+
+func (envSource) Keys() []string {
+	env := os.Environ()
+	keys := make([]string, 0, len(env))
+	for _, kv := range env {
+		if idx := strings.Index(kv, "="); idx > 0 {
+			keys = append(keys, kv[:idx])
 		}
-		return "", ErrSourceKeyNotFound
-	})
+	}
+	return keys
+}
+
+func SourceEnv() Source {
+	return envSource{}
 }
 
 type SourceMap map[string]string
@@ -38,6 +67,14 @@ func (src SourceMap) Get(key string) (string, error) {
 	return "", ErrSourceKeyNotFound
 }
 
+func (src SourceMap) Keys() []string {
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
 func SourceUrl(values url.Values) Source {
 	m := make(SourceMap)
 	for k, v := range values {
@@ -54,3 +91,90 @@ func SourceNil() Source {
 		return "", ErrSourceKeyNotFound
 	})
 }
+
+// FileSource wraps another source and falls back to reading the value from a file
+// if the key itself is missing but <key><suffix> is set, e.g. DB_PASSWORD_FILE=/run/secrets/db.
+type FileSource struct {
+	src    Source
+	suffix string
+
+	mu    sync.Mutex
+	files map[string]string
+}
+
+// SourceFileSuffix returns a FileSource wrapping src. If suffix is empty, "_FILE" is used.
+func SourceFileSuffix(src Source, suffix string) *FileSource {
+	if len(suffix) == 0 {
+		suffix = defaultFileSuffix
+	}
+	return &FileSource{
+		src:    src,
+		suffix: suffix,
+		files:  make(map[string]string),
+	}
+}
+
+func (src *FileSource) Get(key string) (string, error) {
+	value, err := src.src.Get(key)
+	if err != nil && !errors.Is(err, ErrSourceKeyNotFound) {
+		return "", err
+	}
+	found := err == nil
+
+	fileKey := key + src.suffix
+	path, err := src.src.Get(fileKey)
+	if errors.Is(err, ErrSourceKeyNotFound) {
+		// the key may have been read from a file before
+		src.mu.Lock()
+		delete(src.files, key)
+		src.mu.Unlock()
+
+		if found {
+			return value, nil
+		}
+		return "", ErrSourceKeyNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return "", fmt.Errorf("%w: both %s and %s are set", ErrSourceKeyConflict, key, fileKey)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read file from %s: %w", fileKey, err)
+	}
+
+	src.mu.Lock()
+	src.files[key] = path
+	src.mu.Unlock()
+
+	// files written by editors or secret stores usually end with a newline
+	value = strings.TrimSuffix(string(content), "\n")
+	value = strings.TrimSuffix(value, "\r")
+	return value, nil
+}
+
+// File reports whether the value of key was read from a file and returns its path.
+func (src *FileSource) File(key string) (string, bool) {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+
+	path, ok := src.files[key]
+	return path, ok
+}
+
+func (src *FileSource) Keys() []string {
+	lister, ok := src.src.(KeyLister)
+	if !ok {
+		return nil
+	}
+	keys := lister.Keys()
+	for _, k := range keys {
+		if strings.HasSuffix(k, src.suffix) {
+			keys = append(keys, strings.TrimSuffix(k, src.suffix))
+		}
+	}
+	return keys
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -41,3 +42,56 @@ func TestSourceNil(t *testing.T) {
 	_, err := SourceNil().Get("missing")
 	assertEqual(t, ErrSourceKeyNotFound, err)
 }
+
+func TestSourceFileSuffix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	err := os.WriteFile(path, []byte("value\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	src := SourceFileSuffix(SourceMap{
+		"plain":           "value",
+		"key_FILE":        path,
+		"both":            "value",
+		"both_FILE":       path,
+		"unreadable_FILE": filepath.Join(dir, "missing"),
+	}, "")
+	testSrc(t, src)
+
+	value, err := src.Get("plain")
+	assertEqual(t, "value", value)
+	assertEqual(t, nil, err)
+
+	file, ok := src.File("key")
+	assertEqual(t, true, ok)
+	assertEqual(t, path, file)
+	_, ok = src.File("plain")
+	assertEqual(t, false, ok)
+
+	// the file is forgotten once the value comes from the key itself
+	inner := SourceMap{"reused_FILE": path}
+	reused := SourceFileSuffix(inner, "")
+	_, _ = reused.Get("reused")
+	delete(inner, "reused_FILE")
+	inner["reused"] = "value"
+	_, _ = reused.Get("reused")
+	_, ok = reused.File("reused")
+	assertEqual(t, false, ok)
+
+	// errors report the file the value was read from
+	var dummy struct{ Key int }
+	err = Parse(Config{Src: src, KeyFmt: KeyFmtKebab()}, &dummy)
+	var parseErr ParseError
+	if assertEqual(t, true, errors.As(err, &parseErr)) {
+		assertEqual(t, path, parseErr[0].File)
+	}
+
+	_, err = src.Get("both")
+	assertEqual(t, true, errors.Is(err, ErrSourceKeyConflict))
+	assertEqual(t, "key conflict: both both and both_FILE are set", err)
+
+	_, err = src.Get("unreadable")
+	assertEqual(t, true, err != nil)
+}
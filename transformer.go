@@ -1,16 +1,28 @@
 package structparse
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 )
 
 const (
-	structTagDefault = "default"
+	structTagDefault  = "default"
+	structTagTrim     = "trim"
+	structTagLower    = "lower"
+	structTagUpper    = "upper"
+	structTagEncoding = "encoding"
 )
 
 var (
 	ErrTransformerSkipKey = errors.New("skip key")
+	ErrUnresolvedVariable = errors.New("unresolved variable")
+	ErrReferenceCycle     = errors.New("reference cycle")
+	ErrInvalidReference   = errors.New("invalid reference")
 )
 
 type Transformer interface {
@@ -23,6 +35,75 @@ func (t TransformFunc) Transform(key, srcValue string, srcErr error, tag reflect
 	return t(key, srcValue, srcErr, tag)
 }
 
+// Field describes the struct field a value is transformed for.
+type Field struct {
+	// Key is the formatted source key.
+	Key         string
+	StructField reflect.StructField
+	// Path is the Go field path, e.g. Nested.FieldA.
+	Path string
+	// ParentKeys are the unformatted key segments of all parent structs.
+	ParentKeys []string
+	// Type is the type the value will be assigned to.
+	Type reflect.Type
+
+	cache *parseCache
+}
+
+type parseCacheEntry struct {
+	value string
+	err   error
+}
+
+// parseCache allows transformers to share results within a single Parse call.
+type parseCache struct {
+	mu      sync.Mutex
+	entries map[string]parseCacheEntry
+}
+
+func newParseCache() *parseCache {
+	return &parseCache{entries: make(map[string]parseCacheEntry)}
+}
+
+func (c *parseCache) get(key string, fn func() (string, error)) (string, error) {
+	if c == nil {
+		return fn()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.entries[key]; ok {
+		return entry.value, entry.err
+	}
+	value, err := fn()
+	c.entries[key] = parseCacheEntry{value: value, err: err}
+	return value, err
+}
+
+// FieldTransformer is like Transformer but receives the full field description.
+// Transformers in Config.Transformers that implement it are called through TransformField.
+type FieldTransformer interface {
+	TransformField(field Field, srcValue string, srcErr error) (string, error)
+}
+
+type FieldTransformFunc func(field Field, srcValue string, srcErr error) (string, error)
+
+func (t FieldTransformFunc) TransformField(field Field, srcValue string, srcErr error) (string, error) {
+	return t(field, srcValue, srcErr)
+}
+
+func (t FieldTransformFunc) Transform(key, srcValue string, srcErr error, tag reflect.StructTag) (string, error) {
+	return t(Field{Key: key, StructField: reflect.StructField{Tag: tag}}, srcValue, srcErr)
+}
+
+func transform(t Transformer, field Field, srcValue string, srcErr error) (string, error) {
+	if ft, ok := t.(FieldTransformer); ok {
+		return ft.TransformField(field, srcValue, srcErr)
+	}
+	return t.Transform(field.Key, srcValue, srcErr, field.StructField.Tag)
+}
+
 func TransformerDefaultValue() Transformer {
 	return TransformFunc(func(key, srcValue string, srcErr error, tag reflect.StructTag) (string, error) {
 		defaultValue, hasDefault := tag.Lookup(structTagDefault)
@@ -32,3 +113,177 @@ func TransformerDefaultValue() Transformer {
 		return srcValue, srcErr
 	})
 }
+
+// TransformerTrim removes leading and trailing whitespace from values of fields tagged with trim:"true".
+func TransformerTrim() Transformer {
+	return FieldTransformFunc(func(field Field, srcValue string, srcErr error) (string, error) {
+		if srcErr != nil || field.StructField.Tag.Get(structTagTrim) != "true" {
+			return srcValue, srcErr
+		}
+		return strings.TrimSpace(srcValue), nil
+	})
+}
+
+// TransformerCaseFold converts values of fields tagged with lower:"true" or upper:"true".
+func TransformerCaseFold() Transformer {
+	return FieldTransformFunc(func(field Field, srcValue string, srcErr error) (string, error) {
+		if srcErr != nil {
+			return srcValue, srcErr
+		}
+		tag := field.StructField.Tag
+		if tag.Get(structTagLower) == "true" {
+			return strings.ToLower(srcValue), nil
+		}
+		if tag.Get(structTagUpper) == "true" {
+			return strings.ToUpper(srcValue), nil
+		}
+		return srcValue, nil
+	})
+}
+
+// TransformerEncoding decodes values of string and []byte fields tagged with
+// encoding:"base64", encoding:"base64url" or encoding:"hex".
+func TransformerEncoding() Transformer {
+	return FieldTransformFunc(func(field Field, srcValue string, srcErr error) (string, error) {
+		encoding, ok := field.StructField.Tag.Lookup(structTagEncoding)
+		if srcErr != nil || !ok {
+			return srcValue, srcErr
+		}
+
+		if typ := field.Type; typ != nil {
+			isString := typ.Kind() == reflect.String
+			isBytes := typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+			if !isString && !isBytes {
+				return "", fmt.Errorf("encoding is not supported for type %s", typ)
+			}
+		}
+
+		var (
+			decoded []byte
+			err     error
+		)
+		switch encoding {
+		case "base64":
+			decoded, err = decodeBase64(base64.StdEncoding, srcValue)
+		case "base64url":
+			decoded, err = decodeBase64(base64.URLEncoding, srcValue)
+		case "hex":
+			decoded, err = hex.DecodeString(srcValue)
+		default:
+			return "", fmt.Errorf("unsupported encoding %s", encoding)
+		}
+		if err != nil {
+			return "", fmt.Errorf("cannot decode as %s: %w", encoding, err)
+		}
+		return string(decoded), nil
+	})
+}
+
+// decodeBase64 decodes padded values with enc and unpadded ones with its raw variant.
+func decodeBase64(enc *base64.Encoding, value string) ([]byte, error) {
+	if strings.HasSuffix(value, "=") || len(value)%4 == 0 {
+		return enc.DecodeString(value)
+	}
+	return enc.WithPadding(base64.NoPadding).DecodeString(value)
+}
+
+// TransformerInterpolate expands ${VAR}, ${VAR:-default} and ${VAR:?message} references
+// in values by looking them up in src. Referenced values are expanded recursively and
+// $$ can be used to produce a literal $.
+func TransformerInterpolate(src Source) Transformer {
+	return TransformFunc(func(key, srcValue string, srcErr error, tag reflect.StructTag) (string, error) {
+		if srcErr != nil {
+			return srcValue, srcErr
+		}
+		return interpolate(src, srcValue, []string{key})
+	})
+}
+
+func interpolate(src Source, value string, stack []string) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 >= len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+
+		case '{':
+			end := findClosingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("%w: unterminated reference in %q", ErrInvalidReference, value)
+			}
+			resolved, err := resolveReference(src, value[i+2:end], stack)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(resolved)
+			i = end
+
+		default:
+			sb.WriteByte('$')
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func findClosingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func resolveReference(src Source, ref string, stack []string) (string, error) {
+	name, op, arg := ref, "", ""
+	if idx := strings.Index(ref, ":"); idx >= 0 && idx+1 < len(ref) && (ref[idx+1] == '-' || ref[idx+1] == '?') {
+		name, op, arg = ref[:idx], ref[idx:idx+2], ref[idx+2:]
+	}
+
+	if len(name) == 0 {
+		return "", fmt.Errorf("%w: empty variable name in ${%s}", ErrInvalidReference, ref)
+	}
+
+	for _, key := range stack {
+		if key == name {
+			return "", fmt.Errorf("%w: %s -> %s", ErrReferenceCycle, strings.Join(stack, " -> "), name)
+		}
+	}
+
+	value, err := src.Get(name)
+	if err != nil && !errors.Is(err, ErrSourceKeyNotFound) {
+		return "", err
+	}
+	if err == nil && len(value) > 0 {
+		return interpolate(src, value, append(stack[:len(stack):len(stack)], name))
+	}
+
+	switch op {
+	case ":-":
+		return interpolate(src, arg, stack)
+	case ":?":
+		if len(arg) == 0 {
+			arg = "required but not set"
+		}
+		return "", fmt.Errorf("%w: %s: %s", ErrUnresolvedVariable, name, arg)
+	}
+	if err == nil {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnresolvedVariable, name)
+}